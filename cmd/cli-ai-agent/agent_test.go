@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// scriptedBackend answers each request with the next scripted response and
+// keeps the requests for inspection.
+type scriptedBackend struct {
+	responses [][]ChatChunk
+	requests  []ChatRequest
+}
+
+func (this *scriptedBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	request.Messages = slices.Clone(request.Messages) // the agent keeps changing its conversation
+	this.requests = append(this.requests, request)
+	if len(this.responses) == 0 {
+		return nil, errors.New("the script has no more responses")
+	}
+	chunks := this.responses[0]
+	this.responses = this.responses[1:]
+	return &replayStream{chunks: chunks}, nil
+}
+
+// reply is a scripted response with just text.
+func reply(content string) []ChatChunk {
+	return []ChatChunk{{Role: "assistant", Content: content}}
+}
+
+// callTool is a scripted response calling one tool.
+func callTool(name string, arguments map[string]interface{}) []ChatChunk {
+	return []ChatChunk{{Role: "assistant", ToolCalls: []ToolCall{{Function: ToolFunction{Name: name, Arguments: arguments}}}}}
+}
+
+// useInput makes text the user's answers to the agent's questions.
+func useInput(t *testing.T, text string) {
+	original := stdin
+	stdin = bufio.NewReader(strings.NewReader(text))
+	t.Cleanup(func() { stdin = original })
+}
+
+// captureStdout returns what run prints to stdout.
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = original }()
+	output := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- content
+	}()
+	run()
+	_ = writer.Close()
+	return string(<-output)
+}
+
+// failingTool fails every call, counting them.
+type failingTool struct {
+	calls int
+}
+
+func (this *failingTool) Name() string        { return "flaky" }
+func (this *failingTool) Description() string { return "Always fails." }
+func (this *failingTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (this *failingTool) RequiresPermission() bool { return false }
+func (this *failingTool) Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) {
+	this.calls++
+	return tools.ToolResult{}, errors.New("python3: command not found")
+}
+
+// newTestAgent returns an agent sandboxed to a temporary directory, with the
+// given tools registered.
+func newTestAgent(t *testing.T, list ...Tool) *Agent {
+	t.Helper()
+	sandbox, err := tools.NewSandbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := NewAgent("test-model", "http://127.0.0.1:0")
+	agent.sandbox = sandbox
+	agent.TerminalOutput = false
+	if err := agent.RegisterTools(list...); err != nil {
+		t.Fatal(err)
+	}
+	return agent
+}
+
+func TestExecuteSkipsRepeatedOperations(t *testing.T) {
+	appendLine := func(path string) map[string]interface{} {
+		return map[string]interface{}{"path": path, "content": "line\n", "append": true}
+	}
+	write := map[string]interface{}{"path": "b.txt", "content": "original\n"}
+	cases := []struct {
+		name  string
+		calls []map[string]interface{}
+		edit  func(path string) // runs between the calls, e.g. another tool changing the file
+		file  string
+		want  string
+	}{
+		{
+			name:  "identical append is executed once",
+			calls: []map[string]interface{}{appendLine("a.txt"), appendLine("a.txt")},
+			file:  "a.txt",
+			want:  "line\n",
+		},
+		{
+			name:  "equivalent paths are the same file",
+			calls: []map[string]interface{}{appendLine("a.txt"), appendLine("./a.txt")},
+			file:  "a.txt",
+			want:  "line\n",
+		},
+		{
+			name:  "write is repeated after another edit",
+			calls: []map[string]interface{}{write, write},
+			edit:  func(path string) { _ = os.WriteFile(path, []byte("modified\n"), 0644) },
+			file:  "b.txt",
+			want:  "original\n",
+		},
+		{
+			name:  "append is repeated after another edit",
+			calls: []map[string]interface{}{appendLine("a.txt"), appendLine("a.txt")},
+			edit:  func(path string) { _ = os.WriteFile(path, []byte("line\nmore\n"), 0644) },
+			file:  "a.txt",
+			want:  "line\nmore\nline\n",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tool := &tools.WriteFileTool{}
+			agent := newTestAgent(t, tool)
+			path := filepath.Join(agent.sandbox.Root, test.file)
+			for i, params := range test.calls {
+				if i > 0 && test.edit != nil {
+					test.edit(path)
+				}
+				if _, err := agent.execute(context.Background(), tool, params); err != nil {
+					t.Fatal(err)
+				}
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.want {
+				t.Errorf("file contains %q, want %q", content, test.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerShortCircuitsFailingTool(t *testing.T) {
+	tool := &failingTool{}
+	agent := newTestAgent(t, tool)
+	agent.toolFailureLimit = 3
+	call := callTool("flaky", map[string]interface{}{})
+	agent.backend = &scriptedBackend{responses: [][]ChatChunk{call, call, call, call}}
+
+	captureStdout(t, func() {
+		if err := agent.ProcessMessage("run it"); err != nil {
+			t.Error(err)
+		}
+	})
+	if tool.calls != 3 {
+		t.Errorf("the tool ran %d times, want 3", tool.calls)
+	}
+	last := agent.conversation[len(agent.conversation)-1]
+	if last.Role != "tool" || !strings.Contains(last.Content, "Tool flaky is unavailable: it failed 3 times in a row") {
+		t.Errorf("the 4th call wasn't short-circuited: %+v", last)
+	}
+}
+
+func TestEmptyResponseShowsNotice(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.backend = &scriptedBackend{responses: [][]ChatChunk{reply(" \n\t")}}
+
+	output := captureStdout(t, func() {
+		if err := agent.ProcessMessage("hello"); err != nil {
+			t.Error(err)
+		}
+	})
+	if !strings.Contains(output, "The model returned no content") {
+		t.Errorf("notice missing from:\n%s", output)
+	}
+	if last := agent.conversation[len(agent.conversation)-1]; last.Role != "user" {
+		t.Errorf("the empty reply was kept: %+v", last)
+	}
+}
+
+func TestToolCallContent(t *testing.T) {
+	cases := []struct {
+		mode   string
+		stored string
+		shown  bool
+	}{
+		{mode: toolCallContentKeep, stored: "Let me check.", shown: true},
+		{mode: toolCallContentDiscard, stored: "", shown: true},
+		{mode: toolCallContentHide, stored: "", shown: false},
+	}
+	for _, test := range cases {
+		t.Run(test.mode, func(t *testing.T) {
+			agent := newTestAgent(t, &failingTool{})
+			agent.toolCallContent = test.mode
+			withContent := callTool("flaky", map[string]interface{}{})
+			withContent[0].Content = "Let me check."
+			agent.backend = &scriptedBackend{responses: [][]ChatChunk{withContent, reply("Done.")}}
+			var shown strings.Builder
+			agent.OnToken = func(role, text string) { shown.WriteString(text) }
+
+			captureStdout(t, func() {
+				if err := agent.ProcessMessage("check it"); err != nil {
+					t.Error(err)
+				}
+			})
+			stored := agent.conversation[len(agent.conversation)-3]
+			if stored.Role != "assistant" || len(stored.ToolCalls) != 1 {
+				t.Fatalf("expected the tool call message, got %+v", stored)
+			}
+			if stored.Content != test.stored {
+				t.Errorf("stored content %q, want %q", stored.Content, test.stored)
+			}
+			if got := strings.Contains(shown.String(), "Let me check."); got != test.shown {
+				t.Errorf("content shown = %t, want %t: %q", got, test.shown, shown.String())
+			}
+		})
+	}
+}
+
+// countingTool succeeds, counting its calls; guarded makes it ask for permission.
+type countingTool struct {
+	name    string
+	guarded bool
+	calls   int
+}
+
+func (this *countingTool) Name() string        { return this.name }
+func (this *countingTool) Description() string { return "Counts its calls." }
+func (this *countingTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer"}},
+		"required":   []string{"n"},
+	}
+}
+func (this *countingTool) RequiresPermission() bool { return this.guarded }
+func (this *countingTool) Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) {
+	this.calls++
+	return tools.ToolResult{Content: "counted"}, nil
+}
+
+func TestAgenticLoop(t *testing.T) {
+	one := map[string]interface{}{"n": 1}
+	cases := []struct {
+		name          string
+		responses     [][]ChatChunk
+		approve       bool
+		maxIterations int
+		wantErr       error
+		wantRequests  int
+		wantCalls     map[string]int
+		wantHistory   []string // role: content of each message after the user's
+	}{
+		{
+			name:         "reply without tools ends the turn",
+			responses:    [][]ChatChunk{reply("Hello.")},
+			wantRequests: 1,
+			wantHistory:  []string{"assistant: Hello."},
+		},
+		{
+			name:         "tool result is sent back to the model",
+			responses:    [][]ChatChunk{callTool("count", one), reply("Counted once.")},
+			wantRequests: 2,
+			wantCalls:    map[string]int{"count": 1},
+			wantHistory:  []string{"assistant: ", "tool: counted", "assistant: Counted once."},
+		},
+		{
+			name:         "invalid arguments are reported to the model",
+			responses:    [][]ChatChunk{callTool("count", nil), reply("Sorry.")},
+			wantRequests: 2,
+			wantHistory:  []string{"assistant: ", "tool: Error: invalid arguments:\n- n: required parameter is missing\nFix the arguments to match the tool's parameters and call it again.", "assistant: Sorry."},
+		},
+		{
+			name:         "unknown tool",
+			responses:    [][]ChatChunk{callTool("teleport", one)},
+			wantRequests: 1,
+			wantHistory:  []string{"assistant: ", "tool: Unknown tool teleport"},
+		},
+		{
+			name:         "denied permission hands the turn back",
+			responses:    [][]ChatChunk{callTool("guarded", one)},
+			wantRequests: 1,
+			wantHistory:  []string{"assistant: ", "tool: Permission denied for guarded"},
+		},
+		{
+			name:         "approved permission runs the tool",
+			responses:    [][]ChatChunk{callTool("guarded", one), reply("Done.")},
+			approve:      true,
+			wantRequests: 2,
+			wantCalls:    map[string]int{"guarded": 1},
+			wantHistory:  []string{"assistant: ", "tool: counted", "assistant: Done."},
+		},
+		{
+			name:          "max iterations",
+			responses:     [][]ChatChunk{callTool("count", one), callTool("count", one), callTool("count", one), reply("unreached")},
+			maxIterations: 2,
+			wantErr:       ErrMaxIterations,
+			wantRequests:  2,
+			wantCalls:     map[string]int{"count": 2},
+			wantHistory:   []string{"assistant: ", "tool: counted", "assistant: ", "tool: counted"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			count, guarded := &countingTool{name: "count"}, &countingTool{name: "guarded", guarded: true}
+			agent := newTestAgent(t, count, guarded)
+			agent.autoApprove = test.approve
+			agent.denyAll = !test.approve
+			agent.MaxIterations = test.maxIterations
+			backend := &scriptedBackend{responses: test.responses}
+			agent.backend = backend
+
+			var err error
+			captureStdout(t, func() { err = agent.ProcessMessage("go") })
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v, want %v", err, test.wantErr)
+			}
+			if len(backend.requests) != test.wantRequests {
+				t.Errorf("made %d requests, want %d", len(backend.requests), test.wantRequests)
+			}
+			if count.calls != test.wantCalls["count"] || guarded.calls != test.wantCalls["guarded"] {
+				t.Errorf("count ran %d times and guarded %d, want %v", count.calls, guarded.calls, test.wantCalls)
+			}
+			var history []string
+			for _, message := range agent.conversation[1:] {
+				history = append(history, message.Role+": "+message.Content)
+			}
+			if !slices.Equal(history, test.wantHistory) {
+				t.Errorf("conversation is\n%q\nwant\n%q", history, test.wantHistory)
+			}
+		})
+	}
+}
+
+func TestRegisterToolRefusesDuplicateNames(t *testing.T) {
+	first := &countingTool{name: "count"}
+	agent := newTestAgent(t, first)
+
+	err := agent.RegisterTool(&countingTool{name: "count"})
+	if err == nil || err.Error() != `a tool named "count" is already registered` {
+		t.Errorf("got %v", err)
+	}
+	if agent.tools["count"] != first {
+		t.Error("the duplicate replaced the registered tool")
+	}
+
+	err = agent.RegisterTools(&countingTool{name: "a"}, &countingTool{name: "b"}, &countingTool{name: "a"}, &countingTool{name: "c"})
+	if err == nil || !strings.Contains(err.Error(), `"a"`) {
+		t.Errorf("got %v", err)
+	}
+	var names []string
+	for _, tool := range agent.ListTools() {
+		names = append(names, tool.Name())
+	}
+	if !slices.Equal(names, []string{"a", "b", "count"}) {
+		t.Errorf("registered %v, want the tools before the duplicate", names)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// autoFormatTools are the tools whose successful writes trigger auto-formatting.
+var autoFormatTools = map[string]bool{
+	"write_file":  true,
+	"modify_file": true,
+	"apply_patch": true,
+}
+
+// autoFormat runs gofmt (or goimports) on a Go file the tool just wrote (the
+// result's tools.MetaPath) and appends the outcome to the tool result,
+// including the reformatted content so the model works from the canonical form.
+func (this *Agent) autoFormat(toolName string, result tools.ToolResult) tools.ToolResult {
+	if !this.autoGofmt || !autoFormatTools[toolName] {
+		return result
+	}
+	path, _ := result.Metadata[tools.MetaPath].(string)
+	if filepath.Ext(path) != ".go" {
+		return result
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return result
+	}
+	formatter := "gofmt"
+	if this.goimports {
+		if _, err := exec.LookPath("goimports"); err == nil {
+			formatter = "goimports"
+		}
+	}
+	output, err := exec.Command(formatter, "-w", path).CombinedOutput()
+	if err != nil {
+		result.Content += fmt.Sprintf("\n\n[auto-format] %s failed (the file may not compile): %v\n%s", formatter, err, output)
+		return result
+	}
+	after, err := os.ReadFile(path)
+	if err != nil || bytes.Equal(before, after) {
+		result.Content += fmt.Sprintf("\n\n[auto-format] %s: no formatting changes.", formatter)
+		return result
+	}
+	this.session.RecordWrite(path, string(after))
+	result.Content += fmt.Sprintf("\n\n[auto-format] %s reformatted %s; its canonical content is now:\n%s", formatter, path, after)
+	return result
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+func TestAutoFormatAfterWrite(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt isn't installed")
+	}
+	const formatted = "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+	agent := newTestAgent(t, &tools.WriteFileTool{})
+	agent.autoGofmt = true
+	agent.autoApprove = true
+	agent.backend = &scriptedBackend{responses: [][]ChatChunk{
+		callTool("write_file", map[string]interface{}{"path": "main.go", "content": "package main\nfunc main(){\nprintln(\"hi\")}\n"}),
+		reply("Done."),
+	}}
+
+	captureStdout(t, func() {
+		if err := agent.ProcessMessage("write main.go"); err != nil {
+			t.Error(err)
+		}
+	})
+	content, err := os.ReadFile(filepath.Join(agent.sandbox.Root, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != formatted {
+		t.Errorf("main.go wasn't formatted:\n%s", content)
+	}
+	result := agent.conversation[2]
+	if result.Role != "tool" || !strings.Contains(result.Content, "[auto-format] gofmt reformatted") || !strings.Contains(result.Content, formatted) {
+		t.Errorf("the tool result doesn't show the formatted content:\n%s", result.Content)
+	}
+}
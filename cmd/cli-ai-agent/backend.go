@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Backend sends chat requests to a model server speaking a particular API.
+// It is also the seam for driving the agentic loop without a server: an
+// Agent's backend can be any implementation that returns scripted chunks,
+// such as ReplayBackend.
+type Backend interface {
+	Chat(ctx context.Context, request ChatRequest) (ChatStream, error)
+}
+
+// ModelLister is implemented by backends that can list the models their
+// server has available.
+type ModelLister interface {
+	Models(ctx context.Context) ([]string, error)
+}
+
+// ChatRequest is a backend-neutral chat request.
+type ChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []Message              `json:"messages"`
+	Tools    []ToolCall             `json:"tools,omitempty"`   // tool definitions (only Function.Name, Description and Parameters are set)
+	Think    interface{}            `json:"think,omitempty"`   // see parseThink; backends without a reasoning control ignore it
+	Options  map[string]interface{} `json:"options,omitempty"` // see modelOption; only the ollama backend sends them
+	Stream   bool                   `json:"stream"`
+}
+
+// ChatChunk is a piece of a response. Thinking and Content are deltas to
+// append; ToolCalls, when present, are complete.
+type ChatChunk struct {
+	Role      string     `json:"role,omitempty"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Usage     *Usage     `json:"usage,omitempty"` // on the last chunk, from backends that report it
+}
+
+// ChatStream yields the chunks of a response; Next returns io.EOF after the
+// last one. Closing it unblocks a pending Next.
+type ChatStream interface {
+	Next() (ChatChunk, error)
+	Close() error
+}
+
+// Values for -backend.
+const (
+	backendOllama    = "ollama"
+	backendOpenAI    = "openai"
+	backendAnthropic = "anthropic"
+)
+
+// collect reads a whole response into a single message.
+func collect(stream ChatStream) (message Message, err error) {
+	defer func() { _ = stream.Close() }()
+	message.Role = "assistant"
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return message, nil
+		}
+		if err != nil {
+			return message, err
+		}
+		message.Thinking += chunk.Thinking
+		message.Content += chunk.Content
+		message.ToolCalls = append(message.ToolCalls, chunk.ToolCalls...)
+	}
+}
+
+// postJSON sends body to url and returns the response, turning a non-2xx
+// status into an error that includes (the start of) the server's explanation.
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}, headers map[string]string) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 != 2 {
+		defer func() { _ = response.Body.Close() }()
+		explanation, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return nil, &statusError{Status: response.Status, Code: response.StatusCode, Explanation: strings.TrimSpace(string(explanation))}
+	}
+	return response, nil
+}
+
+// maxResponseLineBytes bounds one line of a model server's response. A
+// complete (non-streamed) reply arrives as a single line, as does a chunk
+// carrying a tool call that writes a whole file, so lines easily exceed
+// bufio.Scanner's default 64KB limit.
+const maxResponseLineBytes = 64 * 1024 * 1024
+
+// newLineScanner returns a scanner for the lines of a response body.
+func newLineScanner(body io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, maxResponseLineBytes)
+	return scanner
+}
+
+// statusError is a non-2xx response from a model server.
+type statusError struct {
+	Status      string
+	Code        int
+	Explanation string
+}
+
+func (this *statusError) Error() string { return fmt.Sprintf("%s: %s", this.Status, this.Explanation) }
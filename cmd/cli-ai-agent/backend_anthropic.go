@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicVersion   = "2023-06-01"
+	anthropicMaxTokens = 8192 // the API requires a limit on every request
+)
+
+// AnthropicBackend talks to Anthropic's /v1/messages endpoint.
+//
+// Extended thinking is not requested (-think is ignored): its blocks would
+// have to be sent back with their signatures during tool use, and Message
+// doesn't keep those.
+type AnthropicBackend struct {
+	URL    string // e.g. https://api.anthropic.com (a trailing /v1 is accepted)
+	APIKey string
+	Client *http.Client
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+	Stream    bool               `json:"stream"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"` // user or assistant; tool results are sent by the user
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock is a text, tool_use or tool_result block (or, in
+// responses, a thinking block).
+type anthropicContentBlock struct {
+	Type      string      `json:"type"`
+	Text      string      `json:"text,omitempty"`
+	Thinking  string      `json:"thinking,omitempty"`
+	ID        string      `json:"id,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Input     interface{} `json:"input,omitempty"` // an object; {} must still be sent
+	ToolUseID string      `json:"tool_use_id,omitempty"`
+	Content   string      `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// anthropicEvent is a streamed event (a non-streaming response is a single
+// anthropicResponse).
+type anthropicEvent struct {
+	Type         string                `json:"type"`
+	Index        int                   `json:"index"`
+	Message      anthropicResponse     `json:"message"`       // message_start
+	ContentBlock anthropicContentBlock `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"` // text_delta, thinking_delta, input_json_delta
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"` // content_block_delta, message_delta
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicResponse struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+func (this *AnthropicBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	system, messages := toAnthropicMessages(request.Messages)
+	body := anthropicRequest{
+		Model:     request.Model,
+		MaxTokens: anthropicMaxTokens,
+		System:    system,
+		Messages:  messages,
+		Stream:    request.Stream,
+	}
+	for _, tool := range request.Tools {
+		body.Tools = append(body.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: tool.Function.Parameters,
+		})
+	}
+	headers := map[string]string{
+		"x-api-key":         this.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+	url := strings.TrimSuffix(strings.TrimSuffix(this.URL, "/"), "/v1") + "/v1/messages"
+	response, err := postJSON(ctx, this.Client, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+	return &anthropicStream{body: response.Body, scanner: newLineScanner(response.Body), streaming: request.Stream, blocks: map[int]*anthropicContentBlock{}}, nil
+}
+
+// toAnthropicMessages moves system messages into the top-level system prompt
+// and translates the rest into content blocks: tool calls become tool_use
+// blocks and tool results become tool_result blocks sent by the user.
+// Consecutive messages from the same role are merged, since the API expects
+// user and assistant to alternate.
+func toAnthropicMessages(messages []Message) (system string, results []anthropicMessage) {
+	var prompts []string
+	for _, message := range messages {
+		role, blocks := "user", []anthropicContentBlock(nil)
+		switch message.Role {
+		case "system":
+			prompts = append(prompts, message.Content)
+			continue
+		case "tool":
+			blocks = append(blocks, anthropicContentBlock{Type: "tool_result", ToolUseID: message.ToolCallID, Content: message.Content})
+		case "assistant":
+			role = "assistant"
+			if message.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				blocks = append(blocks, anthropicContentBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: orEmpty(call.Function.Arguments)})
+			}
+		default:
+			if message.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: message.Content})
+			}
+		}
+		if len(blocks) == 0 {
+			continue // the API rejects empty content
+		}
+		if last := len(results) - 1; last >= 0 && results[last].Role == role {
+			results[last].Content = append(results[last].Content, blocks...)
+			continue
+		}
+		results = append(results, anthropicMessage{Role: role, Content: blocks})
+	}
+	return strings.Join(prompts, "\n\n"), results
+}
+
+// anthropicStream reads server-sent events (message_start,
+// content_block_start/delta/stop, message_delta, message_stop), or a single
+// JSON message when not streaming. A tool_use block's input arrives as JSON
+// fragments and is emitted as a ToolCall when the block stops.
+type anthropicStream struct {
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	streaming bool
+	blocks    map[int]*anthropicContentBlock // open tool_use blocks by index
+	arguments map[int]string                 // their input so far
+	done      bool
+}
+
+func (this *anthropicStream) Next() (ChatChunk, error) {
+	if this.done {
+		return ChatChunk{}, io.EOF
+	}
+	if !this.streaming {
+		this.done = true
+		var response struct {
+			anthropicResponse
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(this.body).Decode(&response); err != nil {
+			return ChatChunk{}, err
+		}
+		if response.Error != nil {
+			return ChatChunk{}, fmt.Errorf("server error: %s", response.Error.Message)
+		}
+		result := ChatChunk{Role: response.Role}
+		for _, block := range response.Content {
+			switch block.Type {
+			case "text":
+				result.Content += block.Text
+			case "thinking":
+				result.Thinking += block.Thinking
+			case "tool_use":
+				result.ToolCalls = append(result.ToolCalls, ToolCall{
+					ID:       block.ID,
+					Type:     "function",
+					Function: ToolFunction{Name: block.Name, Arguments: block.arguments()},
+				})
+			}
+		}
+		return result, nil
+	}
+	for this.scanner.Scan() {
+		data, ok := strings.CutPrefix(this.scanner.Text(), "data:")
+		if !ok {
+			continue // blank separators and "event:" lines (the data repeats the type)
+		}
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			log.Printf("Error parsing chunk: %v\n", err)
+			continue
+		}
+		switch event.Type {
+		case "message_start":
+			return ChatChunk{Role: event.Message.Role}, nil
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" {
+				block := event.ContentBlock
+				this.blocks[event.Index] = &block
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				return ChatChunk{Content: event.Delta.Text}, nil
+			case "thinking_delta":
+				return ChatChunk{Thinking: event.Delta.Thinking}, nil
+			case "input_json_delta":
+				if this.arguments == nil {
+					this.arguments = map[int]string{}
+				}
+				this.arguments[event.Index] += event.Delta.PartialJSON
+			}
+		case "content_block_stop":
+			if call, ok := this.toolCall(event.Index); ok {
+				return ChatChunk{ToolCalls: []ToolCall{call}}, nil
+			}
+		case "message_stop":
+			this.done = true
+			return ChatChunk{}, io.EOF
+		case "error":
+			if event.Error != nil {
+				return ChatChunk{}, fmt.Errorf("server error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+		}
+	}
+	if err := this.scanner.Err(); err != nil {
+		return ChatChunk{}, err
+	}
+	this.done = true
+	return ChatChunk{}, io.EOF
+}
+
+// toolCall completes the tool_use block at index, if there is one.
+func (this *anthropicStream) toolCall(index int) (ToolCall, bool) {
+	block, ok := this.blocks[index]
+	if !ok {
+		return ToolCall{}, false
+	}
+	delete(this.blocks, index)
+	arguments := block.arguments()
+	if text := strings.TrimSpace(this.arguments[index]); text != "" {
+		if err := json.Unmarshal([]byte(text), &arguments); err != nil {
+			log.Printf("Unable to parse arguments of tool call %s: %v", block.Name, err)
+		}
+	}
+	return ToolCall{
+		ID:       block.ID,
+		Type:     "function",
+		Function: ToolFunction{Name: block.Name, Arguments: arguments},
+	}, true
+}
+
+func (this anthropicContentBlock) arguments() map[string]interface{} {
+	input, _ := this.Input.(map[string]interface{})
+	return orEmpty(input)
+}
+
+func (this *anthropicStream) Close() error { return this.body.Close() }
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OllamaBackend talks to Ollama's /api/chat endpoint. Given several URLs it
+// fails over: when a host can't be reached or is overloaded the request goes
+// to the next one, and whichever host answered is tried first next time.
+type OllamaBackend struct {
+	URLs      []string
+	Client    *http.Client
+	KeepAlive interface{} // see parseKeepAlive; nil leaves it to the server
+
+	preferred atomic.Int32 // index into URLs of the host that last answered
+}
+
+// NewOllamaBackend returns a backend for the comma-separated Ollama URLs.
+func NewOllamaBackend(urls string, client *http.Client) *OllamaBackend {
+	backend := &OllamaBackend{Client: client}
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			backend.URLs = append(backend.URLs, strings.TrimSuffix(url, "/"))
+		}
+	}
+	return backend
+}
+
+func (this *OllamaBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	if len(this.URLs) == 0 {
+		return nil, errors.New("no Ollama URL is configured")
+	}
+	body := OllamaRequest{
+		Model:     request.Model,
+		Messages:  request.Messages,
+		Stream:    request.Stream,
+		Tools:     request.Tools,
+		Think:     request.Think,
+		Options:   request.Options,
+		KeepAlive: this.KeepAlive,
+	}
+	first := int(this.preferred.Load())
+	var err error
+	for attempt := range this.URLs {
+		index := (first + attempt) % len(this.URLs)
+		var response *http.Response
+		response, err = postJSON(ctx, this.Client, this.URLs[index]+"/api/chat", body, nil)
+		if err == nil {
+			this.preferred.Store(int32(index))
+			if len(this.URLs) > 1 {
+				log.Printf("Request served by %s", this.URLs[index])
+			}
+			return &ollamaStream{body: response.Body, scanner: newLineScanner(response.Body)}, nil
+		}
+		if !unavailable(ctx, err) {
+			return nil, err
+		}
+		if attempt+1 < len(this.URLs) {
+			log.Printf("%s is unavailable (%v); trying %s", this.URLs[index], err, this.URLs[(index+1)%len(this.URLs)])
+		}
+	}
+	return nil, err
+}
+
+// URL returns the host requests currently go to first.
+func (this *OllamaBackend) URL() string {
+	if len(this.URLs) == 0 {
+		return ""
+	}
+	return this.URLs[int(this.preferred.Load())%len(this.URLs)]
+}
+
+// Models lists the models pulled on the host requests currently go to first
+// (Ollama's /api/tags).
+func (this *OllamaBackend) Models(ctx context.Context) ([]string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, this.URL()+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := this.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode/100 != 2 {
+		return nil, &statusError{Status: response.Status, Code: response.StatusCode}
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+// unavailable reports whether err means the host couldn't be reached or is too
+// busy, so another host should be tried. Canceled requests and errors about
+// the request itself aren't worth repeating elsewhere.
+func unavailable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.Code == http.StatusServiceUnavailable || status.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// ollamaStream reads Ollama's newline-delimited JSON responses (a single
+// object when not streaming).
+type ollamaStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	done    bool
+}
+
+func (this *ollamaStream) Next() (ChatChunk, error) {
+	for !this.done && this.scanner.Scan() {
+		line := this.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var chunk OllamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			log.Printf("Error parsing chunk: %v\n", err)
+			continue
+		}
+		this.done = chunk.Done
+		result := ChatChunk{
+			Role:      chunk.Message.Role,
+			Thinking:  chunk.Message.Thinking,
+			Content:   chunk.Message.Content,
+			ToolCalls: chunk.Message.ToolCalls,
+		}
+		if chunk.Done {
+			result.Usage = &Usage{
+				PromptTokens:   chunk.PromptEvalCount,
+				OutputTokens:   chunk.EvalCount,
+				Duration:       time.Duration(chunk.TotalDuration),
+				OutputDuration: time.Duration(chunk.EvalDuration),
+			}
+		}
+		return result, nil
+	}
+	if err := this.scanner.Err(); err != nil && !this.done {
+		return ChatChunk{}, err
+	}
+	return ChatChunk{}, io.EOF
+}
+
+func (this *ollamaStream) Close() error { return this.body.Close() }
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OpenAIBackend talks to an OpenAI-compatible /v1/chat/completions endpoint
+// (vLLM, llama.cpp's server, LM Studio, ...).
+type OpenAIBackend struct {
+	URL    string // base URL, e.g. http://localhost:8000 (a trailing /v1 is accepted)
+	APIKey string // sent as a bearer token when set
+	Client *http.Client
+}
+
+type openAIRequest struct {
+	Model           string          `json:"model"`
+	Messages        []openAIMessage `json:"messages"`
+	Tools           []openAITool    `json:"tools,omitempty"`
+	Stream          bool            `json:"stream"`
+	ReasoningEffort string          `json:"reasoning_effort,omitempty"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"content"` // null on assistant messages that only call tools
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIToolFunction `json:"function"`
+}
+
+type openAIToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openAIToolCall struct {
+	Index    int    `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"` // JSON text, streamed in fragments
+	} `json:"function"`
+}
+
+type openAIDelta struct {
+	Role             string           `json:"role"`
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content"`
+	Reasoning        string           `json:"reasoning"`
+	ToolCalls        []openAIToolCall `json:"tool_calls"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Delta        openAIDelta `json:"delta"`   // streaming
+		Message      openAIDelta `json:"message"` // non-streaming
+		FinishReason *string     `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (this *OpenAIBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	body := openAIRequest{
+		Model:    request.Model,
+		Messages: toOpenAIMessages(request.Messages),
+		Stream:   request.Stream,
+	}
+	for _, tool := range request.Tools {
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: openAIToolFunction{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		}})
+	}
+	if level, ok := request.Think.(string); ok {
+		body.ReasoningEffort = level
+	}
+	headers := map[string]string{}
+	if this.APIKey != "" {
+		headers["Authorization"] = "Bearer " + this.APIKey
+	}
+	url := strings.TrimSuffix(strings.TrimSuffix(this.URL, "/"), "/v1") + "/v1/chat/completions"
+	response, err := postJSON(ctx, this.Client, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+	return &openAIStream{body: response.Body, scanner: newLineScanner(response.Body), streaming: request.Stream, calls: map[int]*openAIToolCall{}}, nil
+}
+
+// toOpenAIMessages translates the conversation: tool calls carry their
+// arguments as JSON text, and tool results name the call they answer.
+func toOpenAIMessages(messages []Message) (results []openAIMessage) {
+	for _, message := range messages {
+		content := message.Content
+		result := openAIMessage{Role: message.Role, Content: &content, ToolCallID: message.ToolCallID}
+		for _, call := range message.ToolCalls {
+			arguments, _ := json.Marshal(orEmpty(call.Function.Arguments))
+			translated := openAIToolCall{ID: call.ID, Type: "function"}
+			translated.Function.Name = call.Function.Name
+			translated.Function.Arguments = string(arguments)
+			result.ToolCalls = append(result.ToolCalls, translated)
+		}
+		if len(result.ToolCalls) > 0 && content == "" {
+			result.Content = nil
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// orEmpty returns an empty map for nil so arguments encode as {} rather than null.
+func orEmpty(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return map[string]interface{}{}
+	}
+	return values
+}
+
+// openAIStream reads server-sent events ("data: {...}" lines ending with
+// "data: [DONE]"), or a single JSON object when not streaming. Tool calls
+// arrive as fragments keyed by index and are assembled until the choice
+// finishes.
+type openAIStream struct {
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	streaming bool
+	calls     map[int]*openAIToolCall
+	done      bool
+}
+
+func (this *openAIStream) Next() (ChatChunk, error) {
+	if this.done {
+		return ChatChunk{}, io.EOF
+	}
+	if !this.streaming {
+		this.done = true
+		var response openAIResponse
+		if err := json.NewDecoder(this.body).Decode(&response); err != nil {
+			return ChatChunk{}, err
+		}
+		if response.Error != nil {
+			return ChatChunk{}, fmt.Errorf("server error: %s", response.Error.Message)
+		}
+		if len(response.Choices) == 0 {
+			return ChatChunk{}, io.EOF
+		}
+		message := response.Choices[0].Message
+		for i := range message.ToolCalls {
+			message.ToolCalls[i].Index = i // only streamed fragments carry an index
+		}
+		this.accumulate(message.ToolCalls)
+		return this.chunk(message, true), nil
+	}
+	for this.scanner.Scan() {
+		data, ok := strings.CutPrefix(this.scanner.Text(), "data:")
+		if !ok {
+			continue // blank separators, comments, "event:" lines
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			this.done = true
+			return this.chunk(openAIDelta{}, true), nil
+		}
+		var response openAIResponse
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			log.Printf("Error parsing chunk: %v\n", err)
+			continue
+		}
+		if response.Error != nil {
+			return ChatChunk{}, fmt.Errorf("server error: %s", response.Error.Message)
+		}
+		if len(response.Choices) == 0 {
+			continue
+		}
+		choice := response.Choices[0]
+		this.accumulate(choice.Delta.ToolCalls)
+		return this.chunk(choice.Delta, choice.FinishReason != nil), nil
+	}
+	if err := this.scanner.Err(); err != nil {
+		return ChatChunk{}, err
+	}
+	this.done = true
+	return this.chunk(openAIDelta{}, true), nil
+}
+
+func (this *openAIStream) accumulate(fragments []openAIToolCall) {
+	for _, fragment := range fragments {
+		call, ok := this.calls[fragment.Index]
+		if !ok {
+			call = &openAIToolCall{Index: fragment.Index}
+			this.calls[fragment.Index] = call
+		}
+		if fragment.ID != "" {
+			call.ID = fragment.ID
+		}
+		call.Function.Name += fragment.Function.Name
+		call.Function.Arguments += fragment.Function.Arguments
+	}
+}
+
+// chunk converts a delta, attaching the assembled tool calls once the
+// response is finished.
+func (this *openAIStream) chunk(delta openAIDelta, finished bool) ChatChunk {
+	result := ChatChunk{
+		Role:     delta.Role,
+		Thinking: delta.ReasoningContent + delta.Reasoning,
+		Content:  delta.Content,
+	}
+	if finished {
+		result.ToolCalls = this.completedToolCalls()
+	}
+	return result
+}
+
+func (this *openAIStream) completedToolCalls() (results []ToolCall) {
+	indexes := make([]int, 0, len(this.calls))
+	for index := range this.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		call := this.calls[index]
+		arguments := map[string]interface{}{}
+		if text := strings.TrimSpace(call.Function.Arguments); text != "" {
+			if err := json.Unmarshal([]byte(text), &arguments); err != nil {
+				log.Printf("Unable to parse arguments of tool call %s: %v", call.Function.Name, err)
+			}
+		}
+		results = append(results, ToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: ToolFunction{Name: call.Function.Name, Arguments: arguments},
+		})
+	}
+	clear(this.calls)
+	return results
+}
+
+func (this *openAIStream) Close() error { return this.body.Close() }
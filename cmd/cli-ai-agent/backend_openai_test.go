@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToOpenAIMessages(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "list the files"},
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{ID: "call_1", Function: ToolFunction{Name: "list_directory", Arguments: map[string]interface{}{"path": "."}}},
+			{ID: "call_2", Function: ToolFunction{Name: "git_info"}},
+		}},
+		{Role: "tool", Content: "a.go\nb.go", ToolCallID: "call_1"},
+	}
+	encoded, err := json.Marshal(toOpenAIMessages(messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"role":"user","content":"list the files"},` +
+		`{"role":"assistant","content":null,"tool_calls":[` +
+		`{"id":"call_1","type":"function","function":{"name":"list_directory","arguments":"{\"path\":\".\"}"}},` +
+		`{"id":"call_2","type":"function","function":{"name":"git_info","arguments":"{}"}}]},` +
+		`{"role":"tool","content":"a.go\nb.go","tool_call_id":"call_1"}]`
+	if string(encoded) != want {
+		t.Errorf("got  %s\nwant %s", encoded, want)
+	}
+}
+
+// openAIServer answers every request with response and keeps the last request body.
+func openAIServer(t *testing.T, response string, request *openAIRequest) *OpenAIBackend {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("requested %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Error(err)
+		}
+		_, _ = io.WriteString(writer, response)
+	}))
+	t.Cleanup(server.Close)
+	return &OpenAIBackend{URL: server.URL + "/v1", Client: server.Client()}
+}
+
+// readChat sends one request and collects the whole response.
+func readChat(t *testing.T, backend Backend, request ChatRequest) (message Message) {
+	t.Helper()
+	stream, err := backend.Chat(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stream.Close() }()
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return message
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		message.Thinking += chunk.Thinking
+		message.Content += chunk.Content
+		message.ToolCalls = append(message.ToolCalls, chunk.ToolCalls...)
+	}
+}
+
+func TestOpenAIBackendToolCalls(t *testing.T) {
+	sse := func(deltas ...string) string {
+		var events strings.Builder
+		for _, delta := range deltas {
+			fmt.Fprintf(&events, "data: {\"choices\":[%s]}\n\n", delta)
+		}
+		return events.String() + "data: [DONE]\n\n"
+	}
+	wantCalls := []ToolCall{
+		{ID: "call_a", Type: "function", Function: ToolFunction{Name: "read_file", Arguments: map[string]interface{}{"path": "main.go"}}},
+		{ID: "call_b", Type: "function", Function: ToolFunction{Name: "grep", Arguments: map[string]interface{}{"pattern": "TODO", "max": float64(5)}}},
+	}
+	cases := []struct {
+		name     string
+		stream   bool
+		response string
+	}{
+		{
+			name:   "streamed fragments are assembled by index",
+			stream: true,
+			response: sse(
+				`{"delta":{"role":"assistant","reasoning_content":"Two lookups."}}`,
+				`{"delta":{"content":"Checking."}}`,
+				`{"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"read_","arguments":""}}]}}`,
+				`{"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"grep","arguments":"{\"pattern\":"}}]}}`,
+				`{"delta":{"tool_calls":[{"index":0,"function":{"name":"file","arguments":"{\"path\":\"main.go\"}"}}]}}`,
+				`{"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"TODO\",\"max\":5}"}}]},"finish_reason":"tool_calls"}`,
+			),
+		},
+		{
+			name: "complete response",
+			response: `{"choices":[{"message":{"role":"assistant","content":"Checking.","reasoning_content":"Two lookups.","tool_calls":[` +
+				`{"id":"call_a","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"main.go\"}"}},` +
+				`{"id":"call_b","type":"function","function":{"name":"grep","arguments":"{\"pattern\":\"TODO\",\"max\":5}"}}]},` +
+				`"finish_reason":"tool_calls"}]}`,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			var sent openAIRequest
+			backend := openAIServer(t, test.response, &sent)
+			message := readChat(t, backend, ChatRequest{
+				Model:    "local",
+				Stream:   test.stream,
+				Messages: []Message{{Role: "user", Content: "find TODOs in main.go"}},
+				Tools: []ToolCall{{Type: "function", Function: ToolFunction{
+					Name:        "read_file",
+					Description: "Read a file",
+					Parameters:  map[string]interface{}{"type": "object"},
+				}}},
+			})
+
+			if len(sent.Tools) != 1 || sent.Tools[0].Type != "function" || sent.Tools[0].Function.Name != "read_file" || sent.Tools[0].Function.Description != "Read a file" {
+				t.Errorf("sent tools %+v", sent.Tools)
+			}
+			if sent.Stream != test.stream || sent.Model != "local" {
+				t.Errorf("sent %+v", sent)
+			}
+			if message.Thinking != "Two lookups." || message.Content != "Checking." {
+				t.Errorf("got thinking %q and content %q", message.Thinking, message.Content)
+			}
+			if !reflect.DeepEqual(message.ToolCalls, wantCalls) {
+				t.Errorf("got tool calls %+v\nwant %+v", message.ToolCalls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestOpenAIBackendServerError(t *testing.T) {
+	var sent openAIRequest
+	backend := openAIServer(t, "data: {\"error\":{\"message\":\"model not loaded\"}}\n\n", &sent)
+	stream, err := backend.Chat(context.Background(), ChatRequest{Model: "local", Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stream.Close() }()
+	if _, err := stream.Next(); err == nil || err.Error() != "server error: model not loaded" {
+		t.Errorf("got %v", err)
+	}
+}
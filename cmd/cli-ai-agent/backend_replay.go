@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// exchange is one line of a -record file: a request and everything the
+// backend answered. ChatError is set when no response arrived; StreamError
+// when the response broke off after Chunks.
+type exchange struct {
+	Sequence    int         `json:"sequence"`
+	Request     ChatRequest `json:"request"`
+	Chunks      []ChatChunk `json:"chunks"`
+	ChatError   string      `json:"chat_error,omitempty"`
+	StreamError string      `json:"stream_error,omitempty"`
+}
+
+// RecordingBackend passes requests on to Backend and appends each exchange
+// to a JSON-lines file (see -record) that ReplayBackend can serve later.
+type RecordingBackend struct {
+	Backend
+
+	mu       sync.Mutex
+	file     io.Writer
+	sequence int
+}
+
+func NewRecordingBackend(backend Backend, file io.Writer) *RecordingBackend {
+	return &RecordingBackend{Backend: backend, file: file}
+}
+
+func (this *RecordingBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	this.mu.Lock()
+	this.sequence++
+	recorded := &exchange{Sequence: this.sequence, Request: request}
+	this.mu.Unlock()
+
+	stream, err := this.Backend.Chat(ctx, request)
+	if err != nil {
+		recorded.ChatError = err.Error()
+		this.write(recorded)
+		return nil, err
+	}
+	return &recordingStream{ChatStream: stream, recorder: this, recorded: recorded}, nil
+}
+
+func (this *RecordingBackend) write(recorded *exchange) {
+	line, err := json.Marshal(recorded)
+	if err == nil {
+		this.mu.Lock()
+		_, err = fmt.Fprintf(this.file, "%s\n", line)
+		this.mu.Unlock()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to record request %d: %v\n", recorded.Sequence, err)
+	}
+}
+
+// recordingStream collects the chunks read from a stream and writes the
+// exchange when the stream ends, fails or is closed, whichever is first. It
+// may be closed from another goroutine (see closeOnDone).
+type recordingStream struct {
+	ChatStream
+	recorder *RecordingBackend
+
+	mu       sync.Mutex
+	recorded *exchange
+	written  bool
+}
+
+func (this *recordingStream) Next() (ChatChunk, error) {
+	chunk, err := this.ChatStream.Next()
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	switch {
+	case errors.Is(err, io.EOF):
+		this.finish("")
+	case err != nil:
+		this.finish(err.Error())
+	case !this.written:
+		this.recorded.Chunks = append(this.recorded.Chunks, chunk)
+	}
+	return chunk, err
+}
+
+func (this *recordingStream) Close() error {
+	this.mu.Lock()
+	this.finish("stream closed before the response ended")
+	this.mu.Unlock()
+	return this.ChatStream.Close()
+}
+
+// finish writes the exchange, unless it already was; the caller holds mu.
+func (this *recordingStream) finish(streamError string) {
+	if !this.written {
+		this.written = true
+		this.recorded.StreamError = streamError
+		this.recorder.write(this.recorded)
+	}
+}
+
+// ReplayBackend answers requests from a -record file instead of a model
+// server. Requests are matched to the recording by their order; one that
+// differs from the recorded request (other than in the tools offered) is an
+// error, since the recorded response no longer applies.
+type ReplayBackend struct {
+	mu        sync.Mutex
+	exchanges []exchange
+	next      int
+}
+
+// LoadReplayBackend reads a file written with -record.
+func LoadReplayBackend(path string) (*ReplayBackend, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	result := &ReplayBackend{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024) // a line holds a whole conversation
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var recorded exchange
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		result.exchanges = append(result.exchanges, recorded)
+	}
+	return result, scanner.Err()
+}
+
+func (this *ReplayBackend) Chat(ctx context.Context, request ChatRequest) (ChatStream, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	sequence := this.next + 1
+	if this.next >= len(this.exchanges) {
+		return nil, fmt.Errorf("replay: request %d was not recorded (the recording has %d)", sequence, len(this.exchanges))
+	}
+	recorded := this.exchanges[this.next]
+	if divergence := diverges(recorded.Request, request); divergence != "" {
+		return nil, fmt.Errorf("replay: request %d diverges from the recording: %s", sequence, divergence)
+	}
+	this.next++
+	if recorded.ChatError != "" {
+		return nil, fmt.Errorf("replay: %s", recorded.ChatError)
+	}
+	return &replayStream{chunks: recorded.Chunks, err: recorded.StreamError}, nil
+}
+
+// diverges describes the first difference between a recorded request and a
+// new one, or returns "" when they match.
+func diverges(recorded, actual ChatRequest) string {
+	if recorded.Model != actual.Model {
+		return fmt.Sprintf("model is %q, was %q", actual.Model, recorded.Model)
+	}
+	for i := range max(len(recorded.Messages), len(actual.Messages)) {
+		if i >= len(actual.Messages) {
+			return fmt.Sprintf("%d messages, %d recorded", len(actual.Messages), len(recorded.Messages))
+		}
+		if i >= len(recorded.Messages) {
+			return fmt.Sprintf("%d messages, only %d recorded", len(actual.Messages), len(recorded.Messages))
+		}
+		want, _ := json.Marshal(recorded.Messages[i])
+		got, _ := json.Marshal(actual.Messages[i])
+		if string(want) != string(got) {
+			return fmt.Sprintf("message %d (%s) is\n  %s\nbut was recorded as\n  %s",
+				i+1, actual.Messages[i].Role, truncateForDisplay(string(got)), truncateForDisplay(string(want)))
+		}
+	}
+	if !slices.Equal(toolNames(recorded.Tools), toolNames(actual.Tools)) {
+		// Reported, not fatal: the responses don't depend on the exact set offered.
+		fmt.Fprintf(os.Stderr, "replay: tools offered %v, recorded %v\n", toolNames(actual.Tools), toolNames(recorded.Tools))
+	}
+	return ""
+}
+
+func toolNames(definitions []ToolCall) (names []string) {
+	for _, definition := range definitions {
+		names = append(names, definition.Function.Name)
+	}
+	return names
+}
+
+func truncateForDisplay(text string) string {
+	const limit = 300
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "…"
+}
+
+// replayStream yields recorded chunks, then the recorded stream error (if
+// any) or io.EOF.
+type replayStream struct {
+	chunks []ChatChunk
+	err    string
+	closed atomic.Bool
+}
+
+func (this *replayStream) Next() (ChatChunk, error) {
+	if this.closed.Load() {
+		return ChatChunk{}, errors.New("stream closed")
+	}
+	if len(this.chunks) > 0 {
+		chunk := this.chunks[0]
+		this.chunks = this.chunks[1:]
+		return chunk, nil
+	}
+	if this.err != "" {
+		return ChatChunk{}, fmt.Errorf("replay: %s", this.err)
+	}
+	return ChatChunk{}, io.EOF
+}
+
+func (this *replayStream) Close() error {
+	this.closed.Store(true)
+	return nil
+}
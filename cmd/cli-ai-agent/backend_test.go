@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBackendsReadLinesOverScannerDefault(t *testing.T) {
+	large := strings.Repeat("x", 200*1024) // over bufio.Scanner's 64KB default
+	cases := []struct {
+		name     string
+		backend  func(url string, client *http.Client) Backend
+		stream   bool
+		response string
+	}{
+		{
+			name:     "ollama streaming",
+			backend:  func(url string, client *http.Client) Backend { return NewOllamaBackend(url, client) },
+			stream:   true,
+			response: fmt.Sprintf(`{"message":{"role":"assistant","content":"%s"},"done":false}`+"\n"+`{"message":{"role":"assistant","content":""},"done":true}`+"\n", large),
+		},
+		{
+			name:     "ollama complete response",
+			backend:  func(url string, client *http.Client) Backend { return NewOllamaBackend(url, client) },
+			response: fmt.Sprintf(`{"message":{"role":"assistant","content":"%s"},"done":true}`, large),
+		},
+		{
+			name:     "openai",
+			backend:  func(url string, client *http.Client) Backend { return &OpenAIBackend{URL: url, Client: client} },
+			stream:   true,
+			response: fmt.Sprintf("data: {\"choices\":[{\"delta\":{\"content\":\"%s\"}}]}\n\ndata: [DONE]\n\n", large),
+		},
+		{
+			name: "anthropic",
+			backend: func(url string, client *http.Client) Backend {
+				return &AnthropicBackend{URL: url, APIKey: "key", Client: client}
+			},
+			stream: true,
+			response: "event: content_block_delta\n" +
+				fmt.Sprintf("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"%s\"}}\n\n", large) +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				_, _ = io.WriteString(writer, test.response)
+			}))
+			defer server.Close()
+
+			message := readChat(t, test.backend(server.URL, server.Client()), ChatRequest{Model: "m", Stream: test.stream})
+			if message.Content != large {
+				t.Errorf("got %d bytes of content, want %d", len(message.Content), len(large))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// placeholder returns a valid-looking value for a parameter of the given schema.
+func placeholder(schema map[string]interface{}) interface{} {
+	if values, ok := schema["enum"].([]string); ok && len(values) > 0 {
+		return values[0]
+	}
+	switch schema["type"] {
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return false
+	case "array":
+		return []interface{}{"placeholder"}
+	case "object":
+		return map[string]interface{}{}
+	}
+	return "placeholder"
+}
+
+// useThrowawayRepo makes the working directory a new, empty git repository,
+// so tools that default to it can't touch the repository under test.
+func useThrowawayRepo(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init: %v: %s", err, output)
+	}
+	t.Chdir(dir)
+}
+
+func TestRequiredParametersMatchExecute(t *testing.T) {
+	useThrowawayRepo(t)
+	builtins := builtinTools(Config{})
+	for _, tool := range builtins {
+		if clipboard, ok := tool.(*tools.ReadClipboardTool); ok {
+			clipboard.Runner = func(ctx context.Context, command tools.Command) ([]byte, error) { return nil, nil }
+		}
+	}
+	agent := newTestAgent(t, builtins...)
+	for _, tool := range agent.ListTools() {
+		t.Run(tool.Name(), func(t *testing.T) {
+			encoded, err := json.Marshal(tool.Parameters())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var schema struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+				Required   []string                          `json:"required"`
+			}
+			if err := json.Unmarshal(encoded, &schema); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range schema.Required {
+				if _, ok := schema.Properties[name]; !ok {
+					t.Errorf("required parameter %s isn't among the properties", name)
+				}
+			}
+			// Leaving out any required parameter fails, naming it, before the tool does anything.
+			for _, missing := range schema.Required {
+				params := map[string]interface{}{}
+				for _, name := range schema.Required {
+					if name != missing {
+						params[name] = placeholder(tool.Parameters()["properties"].(map[string]interface{})[name].(map[string]interface{}))
+					}
+				}
+				_, err := tool.Execute(context.Background(), params)
+				if err == nil || !strings.Contains(err.Error(), missing) {
+					t.Errorf("without %s, Execute gave %v", missing, err)
+				}
+			}
+			// With every required parameter given, Execute doesn't demand another one
+			// (some are only needed for a particular operation, which the error then names).
+			params := map[string]interface{}{}
+			for _, name := range schema.Required {
+				params[name] = placeholder(tool.Parameters()["properties"].(map[string]interface{})[name].(map[string]interface{}))
+			}
+			if requiresPermission(tool, params) {
+				return // it would write files, run commands or go online for real
+			}
+			captureStdout(t, func() { _, err = tool.Execute(context.Background(), params) })
+			if err != nil && strings.HasSuffix(err.Error(), "parameter is required") {
+				t.Errorf("Execute demands a parameter that isn't declared required: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// closeOnDone closes body as soon as ctx is done, unblocking a reader waiting
+// on it (e.g. a scanner stuck on a stalled stream). The returned stop function
+// must be called when reading is finished; it waits for the watcher to exit so
+// no goroutine outlives the response.
+func closeOnDone(ctx context.Context, body io.Closer) (stop func()) {
+	finished := make(chan struct{})
+	var watcher sync.WaitGroup
+	watcher.Add(1)
+	go func() {
+		defer watcher.Done()
+		select {
+		case <-ctx.Done():
+			_ = body.Close()
+		case <-finished:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(finished) })
+		watcher.Wait()
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+func TestCancelingSlowStreamReturnsPromptly(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = fmt.Fprintln(writer, `{"message":{"role":"assistant","content":"partial"},"done":false}`)
+		writer.(http.Flusher).Flush()
+		select { // a model that stalls mid-response
+		case <-request.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	agent := newTestAgent(t)
+	agent.backend = NewOllamaBackend(server.URL, server.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agent.OnToken = func(role, text string) { cancel() } // cancel once the stream is flowing
+
+	var err error
+	var elapsed time.Duration
+	captureStdout(t, func() {
+		started := time.Now()
+		err = agent.ProcessMessageContext(ctx, "hello")
+		elapsed = time.Since(started)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want a cancellation", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("took %s to return after canceling", elapsed)
+	}
+	last := agent.conversation[len(agent.conversation)-1]
+	if last.Role != "assistant" || !strings.HasPrefix(last.Content, "partial") || !strings.Contains(last.Content, "[response cut short") {
+		t.Errorf("the partial response wasn't kept: %+v", last)
+	}
+}
+
+func TestCloseOnDoneUnblocksReader(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer func() { _ = writer.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := closeOnDone(ctx, reader)
+	defer stop()
+
+	read := make(chan error)
+	go func() {
+		_, err := reader.Read(make([]byte, 1)) // nothing is ever written
+		read <- err
+	}()
+	cancel()
+	select {
+	case err := <-read:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("got %v, want the closed body's error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the read stayed blocked after canceling")
+	}
+}
+
+// slowTool takes a while, or fails when its context ends first.
+type slowTool struct{ took time.Duration }
+
+func (this *slowTool) Name() string        { return "slow" }
+func (this *slowTool) Description() string { return "Takes a while." }
+func (this *slowTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+}
+func (this *slowTool) RequiresPermission() bool { return false }
+func (this *slowTool) Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) {
+	select {
+	case <-time.After(this.took):
+		return tools.ToolResult{Content: "finished"}, nil
+	case <-ctx.Done():
+		return tools.ToolResult{}, context.Cause(ctx)
+	}
+}
+
+func TestRequestTimeoutDoesNotLimitTools(t *testing.T) {
+	agent := newTestAgent(t, &slowTool{took: 200 * time.Millisecond})
+	agent.requestTimeout = 50 * time.Millisecond
+	agent.backend = &scriptedBackend{responses: [][]ChatChunk{
+		callTool("slow", map[string]interface{}{}),
+		reply("Done."),
+	}}
+
+	var err error
+	captureStdout(t, func() { err = agent.ProcessMessage("go slowly") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []string
+	for _, message := range agent.conversation {
+		if message.Role == "tool" {
+			results = append(results, message.Content)
+		}
+	}
+	if len(results) != 1 || results[0] != "finished" {
+		t.Errorf("tool results %q, want the slow tool to finish", results)
+	}
+}
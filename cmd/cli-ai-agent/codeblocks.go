@@ -0,0 +1,152 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeBlock is a fenced code block found in an assistant message.
+type codeBlock struct {
+	Language string
+	Filename string
+	Content  string
+}
+
+var (
+	fenceLine      = regexp.MustCompile("^\\s*(```+|~~~+)\\s*(.*)$")
+	fenceFileAttr  = regexp.MustCompile(`(?:title|file|filename|path)\s*=\s*"?([^"\s]+)"?`)
+	mentionedPath  = regexp.MustCompile("[`*\"']?((?:[\\w.-]+/)*[\\w-][\\w.-]*\\.[A-Za-z0-9]+)[`*\"':]*\\s*$")
+	looksLikePath  = regexp.MustCompile(`^(?:[\w.-]+/)*[\w-][\w.-]*\.[A-Za-z0-9]+$`)
+	languageColons = regexp.MustCompile(`^([\w+-]+):(\S+)$`)
+)
+
+// extractCodeBlocks finds the fenced code blocks in text, detecting a suggested
+// filename from the fence info string (e.g. "```go main.go", "```go title=main.go",
+// "```go:main.go") or from a path mentioned at the end of the preceding line.
+func extractCodeBlocks(text string) (blocks []codeBlock) {
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		match := fenceLine.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		fence := match[1]
+		block := parseFenceInfo(match[2])
+		if block.Filename == "" {
+			block.Filename = precedingPath(lines[:i])
+		}
+		var content []string
+		for i++; i < len(lines); i++ {
+			if isClosingFence(lines[i], fence) {
+				break
+			}
+			content = append(content, lines[i])
+		}
+		block.Content = strings.Join(content, "\n") + "\n"
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func isClosingFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) >= len(fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+func parseFenceInfo(info string) (block codeBlock) {
+	info = strings.TrimSpace(info)
+	if attr := fenceFileAttr.FindStringSubmatch(info); attr != nil {
+		block.Filename = attr[1]
+		info = strings.TrimSpace(fenceFileAttr.ReplaceAllString(info, ""))
+	}
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return block
+	}
+	if parts := languageColons.FindStringSubmatch(fields[0]); parts != nil {
+		block.Language = parts[1]
+		if block.Filename == "" && looksLikePath.MatchString(parts[2]) {
+			block.Filename = parts[2]
+		}
+		return block
+	}
+	if looksLikePath.MatchString(fields[0]) && len(fields) == 1 {
+		block.Filename = fields[0]
+		return block
+	}
+	block.Language = fields[0]
+	if block.Filename == "" && len(fields) > 1 && looksLikePath.MatchString(fields[1]) {
+		block.Filename = fields[1]
+	}
+	return block
+}
+
+// precedingPath returns a path mentioned at the end of the last non-blank line.
+func precedingPath(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if match := mentionedPath.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+		return ""
+	}
+	return ""
+}
+
+// ExtractCode offers to save each code block in the last assistant message to disk.
+func (this *Agent) ExtractCode() {
+	var last *Message
+	for i := len(this.conversation) - 1; i >= 0; i-- {
+		if this.conversation[i].Role == "assistant" && this.conversation[i].Content != "" {
+			last = &this.conversation[i]
+			break
+		}
+	}
+	if last == nil {
+		fmt.Println("No assistant message to extract code from.")
+		return
+	}
+	blocks := extractCodeBlocks(last.Content)
+	if len(blocks) == 0 {
+		fmt.Println("No fenced code blocks found in the last assistant message.")
+		return
+	}
+	writer, ok := this.tools["write_file"]
+	if !ok {
+		fmt.Println("The write_file tool is not registered.")
+		return
+	}
+	for i, block := range blocks {
+		lineCount := strings.Count(block.Content, "\n")
+		fmt.Printf("\n[%d/%d] %s block, %d line(s)\n", i+1, len(blocks), cmp.Or(block.Language, "untyped"), lineCount)
+		if block.Filename != "" {
+			fmt.Printf("Write to %s? (Y/n, or type another path): ", block.Filename)
+		} else {
+			fmt.Print("Path to write to (leave empty to skip): ")
+		}
+		response := strings.TrimSpace(readInput())
+		path := block.Filename
+		switch strings.ToLower(response) {
+		case "", "y", "yes":
+		case "n", "no":
+			path = ""
+		default:
+			path = response
+		}
+		if path == "" {
+			fmt.Println("Skipped.")
+			continue
+		}
+		if _, err := this.execute(context.Background(), writer, map[string]interface{}{"path": path, "content": block.Content}); err != nil {
+			fmt.Printf("Error writing %s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("Wrote %s (%d line(s)).\n", path, lineCount)
+	}
+}
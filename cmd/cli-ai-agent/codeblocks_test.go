@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	message := "Here is the server:\n\n" +
+		"```go title=\"cmd/server/main.go\"\npackage main\n\nfunc main() {}\n```\n\n" +
+		"And save this as `config/app.yaml`:\n\n" +
+		"```yaml\nport: 8080\n```\n\n" +
+		"Run it with:\n\n```sh\ngo run ./cmd/server\n```\n"
+	want := []codeBlock{
+		{Language: "go", Filename: "cmd/server/main.go", Content: "package main\n\nfunc main() {}\n"},
+		{Language: "yaml", Filename: "config/app.yaml", Content: "port: 8080\n"},
+		{Language: "sh", Content: "go run ./cmd/server\n"},
+	}
+	if got := extractCodeBlocks(message); !slices.Equal(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseFenceInfo(t *testing.T) {
+	cases := map[string]codeBlock{
+		"go main.go":         {Language: "go", Filename: "main.go"},
+		"go:internal/a.go":   {Language: "go", Filename: "internal/a.go"},
+		"main.py":            {Filename: "main.py"},
+		"python":             {Language: "python"},
+		"js filename=app.js": {Language: "js", Filename: "app.js"},
+	}
+	for info, want := range cases {
+		if got := parseFenceInfo(info); got != want {
+			t.Errorf("parseFenceInfo(%q) = %+v, want %+v", info, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// compareModels sends the same prompt (single turn, no tools) to each model
+// and prints the responses in labeled sections with timing.
+func compareModels(models []string, prompt string, newAgent func(model string) *Agent) {
+	type timing struct {
+		model   string
+		elapsed time.Duration
+		err     error
+	}
+	var timings []timing
+	for _, model := range models {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			continue
+		}
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Printf("== Model: %s\n", model)
+		fmt.Println(strings.Repeat("=", 80))
+
+		started := time.Now()
+		err := newAgent(model).ProcessMessage(prompt)
+		elapsed := time.Since(started)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		fmt.Printf("⏱️  %s responded in %s\n\n", model, elapsed.Round(time.Millisecond))
+		timings = append(timings, timing{model: model, elapsed: elapsed, err: err})
+	}
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Println("== Summary")
+	for _, result := range timings {
+		status := "ok"
+		if result.err != nil {
+			status = "error: " + result.err.Error()
+		}
+		fmt.Printf("  %-30s %10s  %s\n", result.model, result.elapsed.Round(time.Millisecond), status)
+	}
+}
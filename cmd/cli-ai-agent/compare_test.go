@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareModelsShowsEachResponse(t *testing.T) {
+	backends := map[string]*scriptedBackend{
+		"alpha": {responses: [][]ChatChunk{reply("The answer is four.")}},
+		"beta":  {responses: [][]ChatChunk{reply("2 + 2 = 4")}},
+	}
+	newAgent := func(model string) *Agent {
+		agent := newTestAgent(t)
+		agent.model = model
+		agent.backend = backends[model]
+		agent.TerminalOutput = true
+		return agent
+	}
+	output := captureStdout(t, func() {
+		compareModels([]string{"alpha", " beta"}, "What is 2 + 2?", newAgent)
+	})
+
+	alpha := strings.Index(output, "== Model: alpha")
+	beta := strings.Index(output, "== Model: beta")
+	if alpha < 0 || beta < alpha {
+		t.Fatalf("expected a section for alpha, then beta:\n%s", output)
+	}
+	if section := output[alpha:beta]; !strings.Contains(section, "The answer is four.") {
+		t.Errorf("alpha's response missing from its section:\n%s", section)
+	}
+	if section := output[beta:]; !strings.Contains(section, "2 + 2 = 4") {
+		t.Errorf("beta's response missing from its section:\n%s", section)
+	}
+	for model, backend := range backends {
+		if len(backend.requests) != 1 || backend.requests[0].Model != model {
+			t.Errorf("%s got requests %+v", model, backend.requests)
+		}
+	}
+}
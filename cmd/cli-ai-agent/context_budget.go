@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// TrimStrategy selects what happens when the conversation outgrows ContextLimit.
+type TrimStrategy int
+
+const (
+	// TrimNone only warns; the model server will truncate the prompt itself.
+	TrimNone TrimStrategy = iota
+	// TrimOldest drops the oldest messages after the preamble.
+	TrimOldest
+	// TrimSummarize replaces the oldest messages with a summary written by the
+	// model (see SummarizeOldest), or a plain digest if that fails.
+	TrimSummarize
+)
+
+const defaultContextLimit = 8192
+
+var trimStrategies = map[string]TrimStrategy{"none": TrimNone, "oldest": TrimOldest, "summarize": TrimSummarize}
+
+func parseTrimStrategy(value string) (TrimStrategy, error) {
+	strategy, ok := trimStrategies[strings.ToLower(value)]
+	if !ok {
+		return TrimNone, fmt.Errorf("invalid trim strategy %q (expected none, oldest, or summarize)", value)
+	}
+	return strategy, nil
+}
+
+// estimateTokens approximates the tokens in messages at four characters per token.
+func estimateTokens(messages []Message) (tokens int) {
+	for _, message := range messages {
+		characters := len(message.Role) + len(message.Content) + len(message.Thinking)
+		if len(message.ToolCalls) > 0 {
+			calls, _ := json.Marshal(message.ToolCalls)
+			characters += len(calls)
+		}
+		tokens += characters/4 + 4 // plus a little per-message overhead
+	}
+	return tokens
+}
+
+// fitContext warns when the conversation is estimated to exceed ContextLimit
+// and, depending on TrimStrategy, trims it back under the limit.
+func (this *Agent) fitContext(ctx context.Context) {
+	if this.ContextLimit <= 0 {
+		return
+	}
+	estimate := estimateTokens(this.conversation)
+	if estimate <= this.ContextLimit {
+		return
+	}
+	log.Printf("⚠️  Conversation is ~%d tokens, over the %d token context limit.", estimate, this.ContextLimit)
+	if this.TrimStrategy == TrimNone {
+		return
+	}
+	count := this.oldestToTrim()
+	if count == 0 {
+		log.Println("Nothing left to trim: the current turn alone exceeds the context limit.")
+		return
+	}
+	switch this.TrimStrategy {
+	case TrimOldest:
+		this.replaceOldest(count)
+	case TrimSummarize:
+		log.Printf("📝 Summarizing %d old message(s)...", count)
+		if err := this.SummarizeOldest(ctx, count); err != nil {
+			log.Printf("Unable to summarize (%v); using a plain digest instead.", err)
+			trimmed := this.conversation[this.preambleLen : this.preambleLen+count]
+			this.replaceOldest(count, Message{Role: "system", Content: "Summary of earlier conversation:" + digest(trimmed)})
+		}
+	}
+	log.Printf("✂️  Trimmed %d old message(s); conversation is now ~%d tokens.", count, estimateTokens(this.conversation))
+}
+
+// oldestToTrim returns how many messages after the preamble to remove to fit
+// the limit. Tool results are never separated from the call that produced
+// them, and the current turn (from the last user message on) is kept.
+func (this *Agent) oldestToTrim() (count int) {
+	keepFrom := len(this.conversation)
+	for i := len(this.conversation) - 1; i >= this.preambleLen; i-- {
+		if this.conversation[i].Role == "user" {
+			keepFrom = i
+			break
+		}
+	}
+	for index := this.preambleLen; index < keepFrom; {
+		index++
+		for index < keepFrom && this.conversation[index].Role == "tool" {
+			index++
+		}
+		count = index - this.preambleLen
+		remaining := withoutRange(this.conversation, this.preambleLen, index)
+		if estimateTokens(remaining) <= this.ContextLimit {
+			break
+		}
+	}
+	return count
+}
+
+func withoutRange(messages []Message, from, to int) []Message {
+	return append(messages[:from:from], messages[to:]...)
+}
+
+const maxDigestExcerpt = 100
+
+// digest describes messages in a few lines, without the model's help: an
+// excerpt of each message and the names of the tools that were called.
+func digest(messages []Message) string {
+	var lines []string
+	for _, message := range messages {
+		text := strings.Join(strings.Fields(message.Content), " ")
+		if len(text) > maxDigestExcerpt {
+			text = text[:maxDigestExcerpt] + "…"
+		}
+		for _, call := range message.ToolCalls {
+			text = strings.TrimSpace(text + fmt.Sprintf(" [called %s]", call.Function.Name))
+		}
+		if text != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", message.Role, text))
+		}
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
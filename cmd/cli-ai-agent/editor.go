@@ -0,0 +1,62 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editArguments opens the tool arguments as JSON in the user's editor and
+// returns the edited arguments. On a parse error the user may re-edit; ok is
+// false if they give up (or the editor can't be run).
+func editArguments(params map[string]interface{}) (edited map[string]interface{}, ok bool) {
+	content, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		fmt.Println("Unable to encode arguments:", err)
+		return nil, false
+	}
+	for {
+		content, err = editInEditor(content, "*.json")
+		if err != nil {
+			fmt.Println("Unable to edit arguments:", err)
+			return nil, false
+		}
+		edited = make(map[string]interface{})
+		if err = json.Unmarshal(content, &edited); err == nil {
+			return edited, true
+		}
+		fmt.Printf("Invalid JSON: %v\nEdit again? (Y/n): ", err)
+		if response := strings.TrimSpace(strings.ToLower(readInput())); response != "" && response != "y" && response != "yes" {
+			return nil, false
+		}
+	}
+}
+
+// editInEditor writes content to a temporary file, opens it in $VISUAL or
+// $EDITOR (default vi), and returns the saved result.
+func editInEditor(content []byte, pattern string) ([]byte, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err = file.Write(content); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+	editor := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi"))
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %v", editor[0], err)
+	}
+	return os.ReadFile(file.Name())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// useFakeEditor makes $EDITOR a script that applies the sed expression to the file it is given.
+func useFakeEditor(t *testing.T, expression string) {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	content := "#!/bin/sh\nsed '" + expression + "' \"$1\" > \"$1.tmp\" && mv \"$1.tmp\" \"$1\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+}
+
+func TestEditArgumentsBeforeRunning(t *testing.T) {
+	useFakeEditor(t, `s/wrong\.txt/right.txt/`)
+	useInput(t, "e\ny\n")
+	agent := newTestAgent(t, &tools.WriteFileTool{})
+	agent.backend = &scriptedBackend{responses: [][]ChatChunk{
+		callTool("write_file", map[string]interface{}{"path": "wrong.txt", "content": "hello\n"}),
+		reply("Written."),
+	}}
+
+	captureStdout(t, func() {
+		if err := agent.ProcessMessage("write hello"); err != nil {
+			t.Error(err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(agent.sandbox.Root, "right.txt")); err != nil {
+		t.Errorf("the edited path wasn't written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(agent.sandbox.Root, "wrong.txt")); err == nil {
+		t.Error("the original path was written")
+	}
+	call := agent.conversation[1].ToolCalls[0]
+	if call.Function.Arguments["path"] != "right.txt" {
+		t.Errorf("the model sees arguments %v, not the edited ones", call.Function.Arguments)
+	}
+}
+
+func TestEditArgumentsRepromptsOnInvalidJSON(t *testing.T) {
+	useFakeEditor(t, `s/}/,}/`) // a trailing comma
+	useInput(t, "n\n")
+	if _, ok := editArguments(map[string]interface{}{"path": "a.txt"}); ok {
+		t.Error("invalid JSON was accepted")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Values for -log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// Events recorded by Agent.events.
+const (
+	eventRequestSent      = "request_sent"
+	eventChunkReceived    = "chunk_received"
+	eventResponseReceived = "response_received"
+	eventToolCalled       = "tool_called"
+	eventToolResult       = "tool_result"
+	eventError            = "error"
+)
+
+// newEventLogger returns the logger the agent records its events with (see
+// the event* constants), and the file to close when done, if any. Events are
+// written to path in format; with no path they go to stderr as JSON lines for
+// -log-format json, and are otherwise discarded, leaving only the human
+// output on stdout.
+func newEventLogger(format, path string) (*slog.Logger, io.Closer, error) {
+	if format != logFormatText && format != logFormatJSON {
+		return nil, nil, fmt.Errorf("invalid -log-format %q (expected text or json)", format)
+	}
+	if path == "" && format == logFormatText {
+		return slog.New(slog.DiscardHandler), nil, nil
+	}
+	var output io.Writer = os.Stderr
+	var closer io.Closer
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		output, closer = file, file
+	}
+	options := &slog.HandlerOptions{Level: slog.LevelDebug} // chunks are logged at debug level
+	if format == logFormatJSON {
+		return slog.New(slog.NewJSONHandler(output, options)), closer, nil
+	}
+	return slog.New(slog.NewTextHandler(output, options)), closer, nil
+}
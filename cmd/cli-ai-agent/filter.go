@@ -0,0 +1,170 @@
+package main
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+)
+
+// DefaultContentFilters strip artifacts that some models leak into the content stream.
+var DefaultContentFilters = []string{
+	`(?s)<think>.*?</think>`,
+	`</?think>`,
+	`<\|im_start\|>(?:system|user|assistant)?\n?`,
+	`<\|im_end\|>`,
+	`<\|eot_id\|>`,
+	`<\|start_header_id\|>\w*<\|end_header_id\|>\n?`,
+	`<\|(?:end|assistant|user|system)\|>`,
+	`</s>`,
+}
+
+// CompileContentFilters compiles the given regular expressions.
+func CompileContentFilters(patterns []string) (results []*regexp.Regexp, err error) {
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, compiled)
+	}
+	return results, nil
+}
+
+// maxFilterHold bounds how much text is held back while waiting for a
+// possible artifact to complete across chunk boundaries.
+const maxFilterHold = 32 * 1024
+
+var (
+	openingTag = regexp.MustCompile(`<([A-Za-z][\w-]*)(?:\s[^<>]*)?>`)
+	tagWord    = regexp.MustCompile(`[A-Za-z_][\w-]*`)
+)
+
+// StreamFilter removes artifacts from streamed content. Text that might be part
+// of an artifact (a '<' followed by what can still become one of the tag names
+// the filters mention, including the body of an unclosed tag such as <think>)
+// is held back until later chunks either complete the artifact or rule it out.
+type StreamFilter struct {
+	filters []*regexp.Regexp
+	words   []string // lower-cased tag names mentioned by the filters
+	pending string
+}
+
+func NewStreamFilter(filters []*regexp.Regexp) *StreamFilter {
+	return &StreamFilter{filters: filters, words: filterWords(filters)}
+}
+
+// filterWords collects the words in the filters' literal text (e.g. "think"
+// and "im_start"), which are the tag names worth holding text back for.
+func filterWords(filters []*regexp.Regexp) (words []string) {
+	var visit func(*syntax.Regexp)
+	visit = func(node *syntax.Regexp) {
+		if node.Op == syntax.OpLiteral {
+			for _, word := range tagWord.FindAllString(string(node.Rune), -1) {
+				words = append(words, strings.ToLower(word))
+			}
+		}
+		for _, sub := range node.Sub {
+			visit(sub)
+		}
+	}
+	for _, filter := range filters {
+		if parsed, err := syntax.Parse(filter.String(), syntax.Perl); err == nil {
+			visit(parsed)
+		}
+	}
+	return words
+}
+
+// Write accepts the next chunk and returns the text that is safe to display.
+func (this *StreamFilter) Write(chunk string) (ready string) {
+	if len(this.filters) == 0 {
+		return chunk
+	}
+	this.pending += chunk
+	for {
+		hold := this.holdIndex()
+		if hold < 0 {
+			return ready + this.Flush()
+		}
+		ready += this.apply(this.pending[:hold])
+		this.pending = this.pending[hold:]
+
+		if len(this.pending) > maxFilterHold {
+			return ready + this.Flush()
+		}
+		if this.hasUnclosedTag(this.pending) {
+			return ready
+		}
+		if filtered := this.apply(this.pending); filtered != this.pending {
+			this.pending = filtered
+			continue
+		}
+		end := strings.IndexByte(this.pending, '>')
+		if end < 0 {
+			return ready // possibly an incomplete artifact
+		}
+		ready += this.pending[:end+1]
+		this.pending = this.pending[end+1:]
+	}
+}
+
+// Flush returns whatever text is still held back, filtered.
+func (this *StreamFilter) Flush() string {
+	remaining := this.apply(this.pending)
+	this.pending = ""
+	return remaining
+}
+
+func (this *StreamFilter) apply(text string) string {
+	for _, filter := range this.filters {
+		text = filter.ReplaceAllString(text, "")
+	}
+	return text
+}
+
+// holdIndex returns the position of the earliest potential artifact start, or -1.
+func (this *StreamFilter) holdIndex() int {
+	for i := 0; i < len(this.pending); i++ {
+		if this.pending[i] == '<' && this.couldBeTag(this.pending[i+1:]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// couldBeTag reports whether the text following a '<' is, or may yet become,
+// a tag named by one of the filters: "think>", "/s>" and "|im_st" may, while
+// "String> " and " b" can't.
+func (this *StreamFilter) couldBeTag(text string) bool {
+	text = strings.TrimLeft(text, "/|")
+	end := 0
+	for end < len(text) && isNameByte(text[end]) {
+		end++
+	}
+	name := strings.ToLower(text[:end])
+	if end == len(text) {
+		// the name may still be growing
+		return name == "" || slices.ContainsFunc(this.words, func(word string) bool { return strings.HasPrefix(word, name) })
+	}
+	return slices.Contains(this.words, name)
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// hasUnclosedTag reports whether text opens a filtered tag, such as <think>,
+// without closing it yet.
+func (this *StreamFilter) hasUnclosedTag(text string) bool {
+	for _, match := range openingTag.FindAllStringSubmatchIndex(text, -1) {
+		name := text[match[2]:match[3]]
+		if !slices.Contains(this.words, strings.ToLower(name)) {
+			continue
+		}
+		if !strings.Contains(text[match[1]:], "</"+name+">") {
+			return true
+		}
+	}
+	return false
+}
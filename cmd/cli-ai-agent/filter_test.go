@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStreamFilter(t *testing.T) {
+	filters, err := CompileContentFilters(DefaultContentFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		chunks []string
+		want   []string // what each Write returns, then what Flush returns
+	}{
+		{
+			name:   "plain text streams through",
+			chunks: []string{"Hello, ", "world."},
+			want:   []string{"Hello, ", "world.", ""},
+		},
+		{
+			name:   "generic type isn't held back",
+			chunks: []string{"Use Vec<String> ", "for the list. ", "More text here."},
+			want:   []string{"Use Vec<String> ", "for the list. ", "More text here.", ""},
+		},
+		{
+			name:   "comparison isn't held back",
+			chunks: []string{"if a < b {", " return }"},
+			want:   []string{"if a < b {", " return }", ""},
+		},
+		{
+			name:   "think block split across chunks",
+			chunks: []string{"Answer: <th", "ink>let me see", " more</th", "ink>42"},
+			want:   []string{"Answer: ", "", "", "42", ""},
+		},
+		{
+			name:   "special token split across chunks",
+			chunks: []string{"done<|im_", "end|> now"},
+			want:   []string{"done", " now", ""},
+		},
+		{
+			name:   "prefix ruled out by the next chunk",
+			chunks: []string{"x <s", "pan>y"},
+			want:   []string{"x ", "<span>y", ""},
+		},
+		{
+			name:   "stray tag of an unclosed block is dropped on flush",
+			chunks: []string{"a<think>never closed"},
+			want:   []string{"a", "never closed"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			filter := NewStreamFilter(filters)
+			var got []string
+			for _, chunk := range test.chunks {
+				got = append(got, filter.Write(chunk))
+			}
+			got = append(got, filter.Flush())
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStreamFilterWithoutFilters(t *testing.T) {
+	filter := NewStreamFilter(nil)
+	if got := filter.Write("<think>kept</think>"); got != "<think>kept</think>" {
+		t.Errorf("got %q", got)
+	}
+}
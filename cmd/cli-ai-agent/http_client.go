@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxIdleConns    = 10
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// newHTTPClient builds the client an Agent uses for all requests to the model
+// server. HTTP/2 is negotiated when the server supports it over TLS.
+func newHTTPClient(maxIdleConns int, idleConnTimeout time.Duration, disableKeepAlives bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConns
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.DisableKeepAlives = disableKeepAlives
+	transport.ForceAttemptHTTP2 = true
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAgentReusesOneClient(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		_, _ = response.Write([]byte(`{"message":{"role":"assistant","content":"Hi."},"done":true}` + "\n"))
+	}))
+	server.Config.ConnState = func(connection net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	agent := NewAgent("test-model", server.URL)
+	agent.TerminalOutput = false
+	backend := agent.backend.(*OllamaBackend)
+	client := backend.Client
+	if client == nil || client == http.DefaultClient {
+		t.Fatalf("the agent should have its own client, got %v", client)
+	}
+	captureStdout(t, func() {
+		for range 3 {
+			if err := agent.ProcessMessage("hello"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	if backend.Client != client {
+		t.Error("the client was replaced between requests")
+	}
+	if count := connections.Load(); count != 1 {
+		t.Errorf("%d connections were opened for 3 requests, want 1", count)
+	}
+}
+
+func TestNewHTTPClientSettings(t *testing.T) {
+	client := newHTTPClient(4, defaultIdleConnTimeout, true)
+	transport := client.Transport.(*http.Transport)
+	if transport.MaxIdleConns != 4 || transport.MaxIdleConnsPerHost != 4 || !transport.DisableKeepAlives || !transport.ForceAttemptHTTP2 {
+		t.Errorf("unexpected transport settings: %+v", transport)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// inlineToolCall covers the shapes models use when they write a tool call into
+// their content instead of emitting structured tool_calls:
+//
+//	{"name": "read_file", "arguments": {...}}
+//	{"name": "read_file", "parameters": {...}}
+//	{"function": {"name": "read_file", "arguments": "{...}"}}
+type inlineToolCall struct {
+	Name       string          `json:"name"`
+	Arguments  json.RawMessage `json:"arguments"`
+	Parameters json.RawMessage `json:"parameters"`
+	Function   *struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// parseInlineToolCalls extracts tool calls embedded in content as JSON (bare,
+// fenced, or wrapped in <tool_call> tags). Only calls naming a registered tool
+// are returned, which keeps ordinary JSON in an answer from being executed.
+func parseInlineToolCalls(content string, registered map[string]Tool) (results []ToolCall) {
+	for offset := 0; offset < len(content); {
+		start := strings.IndexAny(content[offset:], "{[")
+		if start < 0 {
+			break
+		}
+		start += offset
+		decoder := json.NewDecoder(strings.NewReader(content[start:]))
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			offset = start + 1
+			continue
+		}
+		calls := inlineToolCallsFrom(value, registered)
+		if len(calls) == 0 {
+			offset = start + 1 // the value may still contain a call, e.g. {"tool_call": {...}}
+			continue
+		}
+		results = append(results, calls...)
+		offset = start + int(decoder.InputOffset())
+	}
+	return results
+}
+
+func inlineToolCallsFrom(value json.RawMessage, registered map[string]Tool) (results []ToolCall) {
+	var list []json.RawMessage
+	if json.Unmarshal(value, &list) == nil {
+		for _, item := range list {
+			results = append(results, inlineToolCallsFrom(item, registered)...)
+		}
+		return results
+	}
+	var call inlineToolCall
+	if json.Unmarshal(value, &call) != nil {
+		return nil
+	}
+	name, arguments := call.Name, call.Arguments
+	if len(arguments) == 0 {
+		arguments = call.Parameters
+	}
+	if call.Function != nil {
+		name, arguments = call.Function.Name, call.Function.Arguments
+	}
+	if _, ok := registered[name]; !ok {
+		return nil
+	}
+	params, ok := decodeInlineArguments(arguments)
+	if !ok {
+		return nil
+	}
+	return []ToolCall{{Type: "function", Function: ToolFunction{Name: name, Arguments: params}}}
+}
+
+// decodeInlineArguments accepts arguments as an object or as a JSON-encoded
+// string holding an object (the OpenAI convention).
+func decodeInlineArguments(raw json.RawMessage) (map[string]interface{}, bool) {
+	if len(raw) == 0 {
+		return map[string]interface{}{}, true
+	}
+	var encoded string
+	if json.Unmarshal(raw, &encoded) == nil {
+		raw = json.RawMessage(encoded)
+	}
+	var params map[string]interface{}
+	if json.Unmarshal(raw, &params) != nil {
+		return nil, false
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	return params, true
+}
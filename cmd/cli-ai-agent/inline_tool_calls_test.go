@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+func TestInlineToolCallIsExtractedAndExecuted(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		agent := newTestAgent(t, &tools.ReadFileTool{})
+		agent.parseInlineToolCalls = enabled
+		if err := os.WriteFile(filepath.Join(agent.sandbox.Root, "notes.txt"), []byte("remember the milk\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		backend := &scriptedBackend{responses: [][]ChatChunk{
+			reply("I'll read it.\n```json\n{\"name\": \"read_file\", \"arguments\": {\"path\": \"notes.txt\"}}\n```"),
+			reply("It says to remember the milk."),
+		}}
+		agent.backend = backend
+
+		captureStdout(t, func() {
+			if err := agent.ProcessMessage("what's in notes.txt?"); err != nil {
+				t.Error(err)
+			}
+		})
+		if !enabled {
+			if len(backend.requests) != 1 {
+				t.Errorf("disabled: %d requests were made, want 1", len(backend.requests))
+			}
+			continue
+		}
+		if len(agent.conversation) != 4 {
+			t.Fatalf("expected message, call, result, answer; got %+v", agent.conversation)
+		}
+		call, result := agent.conversation[1], agent.conversation[2]
+		if len(call.ToolCalls) != 1 || call.ToolCalls[0].Function.Arguments["path"] != "notes.txt" {
+			t.Errorf("the call wasn't extracted: %+v", call)
+		}
+		if result.Role != "tool" || !strings.Contains(result.Content, "remember the milk") || result.ToolCallID != call.ToolCalls[0].ID {
+			t.Errorf("the call wasn't executed: %+v", result)
+		}
+	}
+}
+
+func TestParseInlineToolCallShapes(t *testing.T) {
+	registered := map[string]Tool{"read_file": &tools.ReadFileTool{}}
+	cases := map[string]int{ // content to the number of calls found
+		`{"name": "read_file", "arguments": {"path": "a"}}`:                                                      1,
+		`<tool_call>{"name": "read_file", "parameters": {"path": "a"}}</tool_call>`:                              1,
+		`{"function": {"name": "read_file", "arguments": "{\"path\": \"a\"}"}}`:                                  1,
+		`[{"name": "read_file", "arguments": {"path": "a"}}, {"name": "read_file", "arguments": {"path": "b"}}]`: 2,
+		`{"name": "delete_everything", "arguments": {}}`:                                                         0,
+		`Here is some JSON: {"name": "Ada", "age": 36}`:                                                          0,
+	}
+	for content, want := range cases {
+		if got := parseInlineToolCalls(content, registered); len(got) != want {
+			t.Errorf("%s: found %d calls, want %d", content, len(got), want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// messageDelimiter, on a line of its own, starts and ends a multi-line message.
+const messageDelimiter = `"""`
+
+// stdin is the one reader of standard input for the whole session, so lines
+// that arrive together (a paste, or piped input) aren't lost between reads.
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line of input without its line ending. The error is only
+// set (to io.EOF, typically) when there is no more input.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readInput reads a line of input, such as the answer to a question.
+func readInput() string {
+	line, _ := readLine()
+	return line
+}
+
+// readMessage reads the user's next message: a single line or, when that line
+// is the delimiter, the lines up to the next delimiter (or the end of input).
+func readMessage() (string, error) {
+	line, err := readLine()
+	if err != nil || strings.TrimSpace(line) != messageDelimiter {
+		return line, err
+	}
+	var lines []string
+	for {
+		line, err := readLine()
+		if err != nil || strings.TrimSpace(line) == messageDelimiter {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
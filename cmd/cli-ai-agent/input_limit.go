@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// Values for -oversized-input.
+const (
+	oversizedInputWarn   = "warn"
+	oversizedInputRefuse = "refuse"
+)
+
+// checkInputSize reports whether a user message may be sent, with a notice for
+// the user when it exceeds limit bytes (a limit of 0 disables the check).
+func checkInputSize(input string, limit int, mode string) (ok bool, notice string) {
+	if limit <= 0 || len(input) <= limit {
+		return true, ""
+	}
+	advice := "Consider writing it to a file and asking the agent to read the parts it needs."
+	if mode == oversizedInputRefuse {
+		return false, fmt.Sprintf("⛔ Message not sent: it is %d bytes, over the %d byte limit (-max-input-bytes). %s", len(input), limit, advice)
+	}
+	return true, fmt.Sprintf("⚠️  This message is %d bytes, over the %d byte limit (-max-input-bytes), and may crowd out the context window. %s", len(input), limit, advice)
+}
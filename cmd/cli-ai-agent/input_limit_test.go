@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckInputSize(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		limit  int
+		mode   string
+		ok     bool
+		notice string
+	}{
+		{name: "within the limit", input: "short", limit: 10, mode: oversizedInputRefuse, ok: true},
+		{name: "exactly the limit", input: "0123456789", limit: 10, mode: oversizedInputRefuse, ok: true},
+		{name: "no limit", input: strings.Repeat("x", 1<<20), limit: 0, mode: oversizedInputRefuse, ok: true},
+		{name: "oversized input warns", input: "0123456789A", limit: 10, mode: oversizedInputWarn, ok: true, notice: "⚠️  This message is 11 bytes, over the 10 byte limit"},
+		{name: "oversized input is refused", input: "0123456789A", limit: 10, mode: oversizedInputRefuse, ok: false, notice: "⛔ Message not sent: it is 11 bytes, over the 10 byte limit"},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			ok, notice := checkInputSize(test.input, test.limit, test.mode)
+			if ok != test.ok {
+				t.Errorf("ok = %t, want %t", ok, test.ok)
+			}
+			if test.notice == "" && notice != "" || !strings.HasPrefix(notice, test.notice) {
+				t.Errorf("notice %q, want one starting %q", notice, test.notice)
+			}
+			if test.notice != "" && !strings.Contains(notice, "writing it to a file") {
+				t.Errorf("notice %q doesn't suggest a file", notice)
+			}
+		})
+	}
+}
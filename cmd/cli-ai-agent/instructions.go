@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// preamble returns the system messages every conversation starts with: the
+// system prompt, then the project instructions.
+func (this *Agent) preamble() (messages []Message) {
+	if this.systemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: this.systemPrompt})
+	}
+	if instructions := this.loadInstructions(); instructions != "" {
+		messages = append(messages, Message{Role: "system", Content: instructions})
+	}
+	return messages
+}
+
+// loadInstructions reads the per-project instructions file, if there is one.
+func (this *Agent) loadInstructions() string {
+	if this.instructionsPath == "" {
+		return ""
+	}
+	raw, err := os.ReadFile(this.instructionsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	if err != nil {
+		log.Printf("Unable to read instructions file %s: %v", this.instructionsPath, err)
+		return ""
+	}
+	content := strings.TrimSpace(string(raw))
+	if content == "" {
+		return ""
+	}
+	return fmt.Sprintf("Project instructions (from %s):\n\n%s", this.instructionsPath, content)
+}
+
+// Reset clears the conversation (keeping the preamble), session state, tool
+// failure counts, and any "allow all" answer. Later turns are saved as a new session.
+func (this *Agent) Reset() {
+	this.conversation = this.preamble()
+	this.sessionName = newSessionName()
+	this.preambleLen = len(this.conversation)
+	this.session.Reset()
+	clear(this.toolFailures)
+	this.autoApprove = this.approveAll
+}
+
+// ReloadPreamble re-reads the instructions file, replacing the system messages
+// at the start of the conversation while keeping the rest of the history.
+func (this *Agent) ReloadPreamble() {
+	preamble := this.preamble()
+	this.conversation = append(preamble, this.conversation[this.preambleLen:]...)
+	this.preambleLen = len(preamble)
+}
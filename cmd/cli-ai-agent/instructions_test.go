@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstructionsFileIsSentAsSystemMessage(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.systemPrompt = "You are helpful."
+	agent.instructionsPath = filepath.Join(agent.sandbox.Root, "CLIAI.md")
+	if err := os.WriteFile(agent.instructionsPath, []byte("Use tabs, not spaces.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backend := &scriptedBackend{responses: [][]ChatChunk{reply("OK.")}}
+	agent.backend = backend
+	agent.Reset()
+
+	if err := agent.ProcessMessage("hello"); err != nil {
+		t.Fatal(err)
+	}
+	messages := backend.requests[0].Messages
+	if len(messages) != 3 || messages[0].Role != "system" || messages[1].Role != "system" || messages[2].Role != "user" {
+		t.Fatalf("expected the system prompt, the instructions, then the message; got %+v", messages)
+	}
+	if !strings.Contains(messages[1].Content, "Use tabs, not spaces.") {
+		t.Errorf("instructions missing from %q", messages[1].Content)
+	}
+}
+
+func TestMissingInstructionsFileIsIgnored(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	agent := newTestAgent(t)
+	agent.instructionsPath = filepath.Join(agent.sandbox.Root, "CLIAI.md")
+	backend := &scriptedBackend{responses: [][]ChatChunk{reply("OK.")}}
+	agent.backend = backend
+	agent.Reset()
+
+	if err := agent.ProcessMessage("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if messages := backend.requests[0].Messages; len(messages) != 1 || messages[0].Role != "user" {
+		t.Errorf("expected only the user message, got %+v", messages)
+	}
+	if logged.Len() > 0 {
+		t.Errorf("a missing file was reported: %s", logged.String())
+	}
+}
+
+func TestSystemPromptIsAlwaysFirst(t *testing.T) {
+	responses := []OllamaResponse{
+		{Message: Message{Role: "assistant", ToolCalls: []ToolCall{{Function: ToolFunction{Name: "flaky", Arguments: map[string]interface{}{}}}}}, Done: true},
+		{Message: Message{Role: "assistant", Content: "It failed."}, Done: true},
+		{Message: Message{Role: "assistant", Content: "Hello again."}, Done: true},
+		{Message: Message{Role: "assistant", Content: "Fresh start."}, Done: true},
+	}
+	var requests []OllamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body OllamaRequest
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, body)
+		_ = json.NewEncoder(writer).Encode(responses[0])
+		responses = responses[1:]
+	}))
+	defer server.Close()
+
+	agent := newTestAgent(t, &failingTool{})
+	agent.systemPrompt = "You are a careful engineer."
+	agent.backend = NewOllamaBackend(server.URL, server.Client())
+	agent.Reset()
+	captureStdout(t, func() {
+		for _, message := range []string{"run it", "hi", "clear", "start over"} {
+			if message == "clear" {
+				agent.Reset()
+				continue
+			}
+			if err := agent.ProcessMessage(message); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	if len(requests) != 4 {
+		t.Fatalf("sent %d requests, want 4", len(requests))
+	}
+	for i, request := range requests {
+		if len(request.Messages) < 2 || request.Messages[0].Role != "system" || request.Messages[0].Content != "You are a careful engineer." {
+			t.Errorf("request %d doesn't start with the system prompt: %+v", i+1, request.Messages)
+		}
+	}
+	if last := requests[3].Messages; len(last) != 2 || last[1].Content != "start over" {
+		t.Errorf("clearing should leave only the system prompt, got %+v", last)
+	}
+}
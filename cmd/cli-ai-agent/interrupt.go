@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// errInterrupted is the cause of a turn canceled with Ctrl-C.
+var errInterrupted = errors.New("interrupted by the user")
+
+// interruptWindow is how soon a second Ctrl-C must follow the first to quit.
+const interruptWindow = 2 * time.Second
+
+// interrupts turns SIGINT into cancellation of the current turn, so a
+// runaway response can be stopped without losing the session. A second
+// SIGINT within interruptWindow exits the program.
+type interrupts struct {
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc // of the current turn, if one is running
+	last   time.Time
+}
+
+func handleInterrupts() *interrupts {
+	this := &interrupts{}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		for range signals {
+			this.interrupt()
+		}
+	}()
+	return this
+}
+
+// turn returns the context for one turn, which the next interrupt cancels,
+// and the function to call when the turn is over.
+func (this *interrupts) turn() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	this.mu.Lock()
+	this.cancel = cancel
+	this.mu.Unlock()
+	return ctx, func() {
+		this.mu.Lock()
+		this.cancel = nil
+		this.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+func (this *interrupts) interrupt() {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	now := time.Now()
+	if now.Sub(this.last) < interruptWindow {
+		fmt.Println("\nExiting.")
+		os.Exit(130)
+	}
+	this.last = now
+	if this.cancel != nil {
+		this.cancel(errInterrupted)
+		this.cancel = nil
+		fmt.Println("\n⏹  Interrupting this turn (press Ctrl-C again to quit).")
+		return
+	}
+	fmt.Println("\n(Press Ctrl-C again to quit, or type 'exit'.)")
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mdw-tools/cli-ai-agent/pretty"
 	"github.com/mdw-tools/cli-ai-agent/tools"
@@ -19,17 +28,128 @@ import (
 var Version = "dev"
 
 type Config struct {
-	Model     string
-	OllamaURL string
+	Model            string
+	OllamaURL        string
+	Backend          string
+	BackendURL       string
+	APIKey           string
+	ContentFilters   []string
+	NoDefaultFilters bool
+	Think            string
+	NoThink          bool
+	HideThinking     bool
+	Options          map[string]interface{}
+	KeepAlive        string
+	SystemPrompt     string
+	SystemPromptFile string
+	Instructions     string
+	Compare          string
+	ToolFailureLimit int
+
+	MaxIdleConns      int
+	IdleConnTimeout   time.Duration
+	DisableKeepAlives bool
+
+	SlashCommands string
+	AutoGofmt     bool
+	AutoMkdir     bool
+	Goimports     bool
+
+	ParseInlineToolCalls bool
+	ToolCallContent      string
+	MaxToolsInPrompt     int
+	RequestTimeout       time.Duration
+	NoStream             bool
+	MaxIterations        int
+	ContextLimit         int
+	Trim                 string
+	SummaryModel         string
+	MaxInputBytes        int
+	OversizedInput       string
+	CoreTools            string
+
+	SessionsDir string
+	Autosave    bool
+	Resume      bool
+
+	Verbose      bool
+	LogFormat    string
+	LogFile      string
+	Record       string
+	Replay       string
+	Yes          bool
+	Root         string
+	AllowedHosts string
+	Prompt       string
+	NoColor      bool
+	Markdown     bool
+	Stats        bool
 }
 
 func main() {
 	log.SetFlags(log.Lshortfile | log.Lmicroseconds)
-	var config Config
+	config := Config{Options: make(map[string]interface{})}
 
 	flags := flag.NewFlagSet(fmt.Sprintf("%s @ %s", filepath.Base(os.Args[0]), Version), flag.ExitOnError)
 	flags.StringVar(&config.Model, "model", "mistral", "The ollama model to use (must already be pulled/downloaded).")
-	flags.StringVar(&config.OllamaURL, "ollama-url", "http://localhost:11434", "The URL of the running ollama instance.")
+	flags.StringVar(&config.OllamaURL, "ollama-url", "http://localhost:11434", "The URL of the running ollama instance, or a comma-separated list of them: requests fail over to the next when one is unreachable or overloaded.")
+	flags.StringVar(&config.Backend, "backend", backendOllama, "The API the model server speaks: ollama, openai (any OpenAI-compatible /v1/chat/completions server, e.g. vLLM or llama.cpp), or anthropic.")
+	flags.StringVar(&config.BackendURL, "backend-url", "", "The URL of the model server (defaults to -ollama-url for ollama, http://localhost:8000 for openai, https://api.anthropic.com for anthropic).")
+	flags.StringVar(&config.APIKey, "api-key", "", "The API key for the openai or anthropic backend (defaults to $OPENAI_API_KEY or $ANTHROPIC_API_KEY).")
+	flags.Func("content-filter", "A regular expression for artifacts to strip from model content (repeatable).", func(value string) error {
+		config.ContentFilters = append(config.ContentFilters, value)
+		return nil
+	})
+	flags.BoolVar(&config.NoDefaultFilters, "no-default-filters", false, "Don't strip the default set of model artifacts (<think> tags, role tokens, etc.).")
+	flags.StringVar(&config.Think, "think", "", "Reasoning control for thinking models: on, off, or a level (low, medium, high). Unset uses the model default.")
+	flags.BoolVar(&config.NoThink, "no-think", false, "Ask the server not to think at all (the same as -think off).")
+	flags.BoolVar(&config.HideThinking, "hide-thinking", false, "Don't display the model's thinking as it streams (it is still kept for the next request).")
+	modelOption(flags, config.Options, "temperature", "temperature", false)
+	modelOption(flags, config.Options, "top-p", "top_p", false)
+	modelOption(flags, config.Options, "top-k", "top_k", true)
+	modelOption(flags, config.Options, "num-ctx", "num_ctx", true)
+	modelOption(flags, config.Options, "seed", "seed", true)
+	modelOption(flags, config.Options, "repeat-penalty", "repeat_penalty", false)
+	flags.StringVar(&config.KeepAlive, "keep-alive", "5m", "How long Ollama keeps the model loaded after a request, e.g. 30m, or -1 to keep it loaded (empty uses the server default).")
+	flags.StringVar(&config.SystemPrompt, "system-prompt", "", "A system prompt to start every conversation with (kept when the conversation is cleared).")
+	flags.StringVar(&config.SystemPromptFile, "system-prompt-file", "", "Read the system prompt from this file (instead of -system-prompt).")
+	flags.StringVar(&config.Instructions, "instructions", "CLIAI.md", "Project instructions file to load as a system message (ignored if absent).")
+	flags.StringVar(&config.Compare, "compare", "", "Comma-separated models (e.g. \"modelA,modelB\") to run a single prompt through, without tools, and compare.")
+	flags.IntVar(&config.ToolFailureLimit, "tool-failure-limit", 3, "Consecutive failures after which a tool is reported unavailable for the rest of the session (0 disables).")
+	flags.IntVar(&config.MaxIdleConns, "max-idle-conns", defaultMaxIdleConns, "Maximum idle (keep-alive) connections to the model server.")
+	flags.DurationVar(&config.IdleConnTimeout, "idle-conn-timeout", defaultIdleConnTimeout, "How long idle connections to the model server are kept open.")
+	flags.BoolVar(&config.DisableKeepAlives, "disable-keep-alives", false, "Open a new connection to the model server for every request.")
+	flags.StringVar(&config.SlashCommands, "slash-commands", "", "JSON file mapping slash-command names to prompt templates ({{args}} is replaced with the command's arguments).")
+	flags.BoolVar(&config.AutoGofmt, "auto-gofmt", false, "Run gofmt on Go files after write_file/modify_file/apply_patch and report the formatted result to the model.")
+	flags.BoolVar(&config.AutoMkdir, "auto-mkdir", false, "Let write_file create missing parent directories unless a call sets create_dirs to false.")
+	flags.BoolVar(&config.Goimports, "goimports", false, "With -auto-gofmt, use goimports instead of gofmt when it is installed.")
+	flags.BoolVar(&config.ParseInlineToolCalls, "parse-inline-tool-calls", false, "When a response has no structured tool calls, look for tool-call JSON written into its content and execute that instead.")
+	flags.StringVar(&config.ToolCallContent, "tool-call-content", toolCallContentKeep, "Content that accompanies tool calls: keep (show and store), discard (show, but leave out of the history), or hide (neither; content is then only shown once the response completes).")
+	flags.IntVar(&config.MaxToolsInPrompt, "max-tools-in-prompt", 0, "Send at most this many tool definitions per request: the core tools plus those most relevant to the message (0 sends all).")
+	flags.StringVar(&config.CoreTools, "core-tools", defaultCoreTools, "Comma-separated tools always sent when -max-tools-in-prompt applies.")
+	flags.IntVar(&config.MaxIterations, "max-iterations", defaultMaxIterations, "Maximum model requests (tool-calling rounds) per message before the turn is cut off.")
+	flags.IntVar(&config.ContextLimit, "context-limit", defaultContextLimit, "The model's context window in tokens (estimated at 4 characters per token); a larger conversation triggers a warning and -trim (0 disables).")
+	flags.StringVar(&config.Trim, "trim", "none", "What to do when the conversation exceeds -context-limit: none (just warn), oldest (drop the oldest messages), or summarize (replace them with a digest).")
+	flags.StringVar(&config.SummaryModel, "summary-model", "", "Model that summarizes old messages for -trim summarize, e.g. a smaller, faster one (defaults to -model).")
+	flags.BoolVar(&config.NoStream, "no-stream", false, "Request complete responses and print each at once, without the spinner (for output piped to a file, CI logs, or scripts).")
+	flags.DurationVar(&config.RequestTimeout, "request-timeout", 0, "Abandon a model request (including its streamed response) after this long, e.g. 5m (0 waits indefinitely).")
+	flags.IntVar(&config.MaxInputBytes, "max-input-bytes", 64*1024, "Size above which a single user message triggers -oversized-input handling (0 disables).")
+	flags.StringVar(&config.OversizedInput, "oversized-input", oversizedInputWarn, "What to do with a user message over -max-input-bytes: warn (send it anyway) or refuse.")
+	flags.StringVar(&config.SessionsDir, "sessions-dir", defaultSessionsDir(), "Directory conversations are saved to and loaded from (empty disables sessions).")
+	flags.BoolVar(&config.Autosave, "autosave", false, "Save the conversation to -sessions-dir after every turn, so -resume can pick it up later.")
+	flags.BoolVar(&config.Resume, "resume", false, "Resume the most recently saved session.")
+	flags.StringVar(&config.Root, "root", "", "Confine file tools to this project directory: paths outside it (including via '..' or symlinks) are refused, and relative paths are resolved against it.")
+	flags.StringVar(&config.AllowedHosts, "allowed-hosts", "", "Comma-separated hosts fetch_url may read from (subdomains included), e.g. \"go.dev,github.com\" (empty allows any host; every fetch still asks permission).")
+	flags.StringVar(&config.Prompt, "prompt", "", "Process this one message and exit (non-zero on error) instead of starting the interactive loop; \"-\" reads it from stdin. Permission-gated tools are denied unless -yes is given.")
+	flags.BoolVar(&config.Stats, "stats", false, "Print the tokens generated, generation speed, and prompt tokens after each turn (when the backend reports them, as Ollama does).")
+	flags.BoolVar(&config.Markdown, "markdown", false, "Render the model's markdown (headers, lists, emphasis, highlighted code blocks) once each reply is complete, instead of streaming it raw.")
+	flags.BoolVar(&config.NoColor, "no-color", false, "Don't color output (it is also left uncolored when stdout isn't a terminal or NO_COLOR is set).")
+	flags.StringVar(&config.Record, "record", "", "Write every model request and its complete response to this JSON-lines file, for -replay.")
+	flags.StringVar(&config.Replay, "replay", "", "Answer model requests from a file written with -record instead of the model server (tools still run). Requests must match the recording, in order.")
+	flags.BoolVar(&config.Yes, "yes", false, "Run permission-gated tools (file writes, shell commands, ...) without asking.")
+	flags.BoolVar(&config.Verbose, "verbose", false, "Log debugging details from tools (e.g. what modify_file read and wrote).")
+	flags.StringVar(&config.LogFormat, "log-format", logFormatText, "Format of the event log (requests, streamed chunks, tool calls and results, errors, with timings): text, or json for one JSON object per line. Written to -log-file, or for json to stderr.")
+	flags.StringVar(&config.LogFile, "log-file", "", "Append the event log to this file, keeping it apart from the interactive output.")
 	flags.Usage = func() {
 		_, _ = fmt.Fprintf(flags.Output(), "Usage of %s:\n", flags.Name())
 		_, _ = fmt.Fprintf(flags.Output(), "%s [args ...]\n", filepath.Base(os.Args[0]))
@@ -38,24 +158,214 @@ func main() {
 	_ = flags.Parse(os.Args[1:])
 
 	log.SetPrefix(fmt.Sprintf("[%s] ", config.Model))
+	tools.Verbose = config.Verbose
+	pretty.Color = !config.NoColor && pretty.ColorSupported(os.Stdout)
 	log.Println("🚀 Agentic AI REPL with Ollama")
 	log.Println("Type 'exit' to end the session.")
+	log.Println(`Type """ on a line of its own to start a multi-line message (e.g. a pasted stack trace), and again to end it.`)
 	log.Println("Type 'clear' to clear conversation history.")
-	log.Printf("Config: %#v", config)
+	log.Println("Type 'reload' to reload the project instructions file.")
+	log.Println("Type 'tools' to list the tools the model can use.")
+	log.Println("Type 'model' to show the current model, or 'model <name>' to switch models mid-conversation.")
+	log.Println("Type 'undo' to revert the last file edit made by a tool.")
+	log.Println("Type 'extract-code' to save code blocks from the last response to files.")
+	log.Println("Type 'search <text>' (or 'search /regexp/') to find earlier messages.")
+	log.Println("Type 'save <name>' or 'load <name>' to save or restore a named session.")
+	log.Println("Type 'run-tool <name> {json-args}' to run a tool directly, outside the conversation.")
+	logged := config
+	if logged.APIKey != "" {
+		logged.APIKey = "(redacted)"
+	}
+	log.Printf("Config: %#v", logged)
+
+	if config.SystemPromptFile != "" {
+		if config.SystemPrompt != "" {
+			log.Fatalln("Use either -system-prompt or -system-prompt-file, not both.")
+		}
+		content, err := os.ReadFile(config.SystemPromptFile)
+		if err != nil {
+			log.Fatalln("Unable to read system prompt:", err)
+		}
+		config.SystemPrompt = strings.TrimSpace(string(content))
+	}
+
+	filterPatterns := config.ContentFilters
+	if !config.NoDefaultFilters {
+		filterPatterns = slices.Concat(DefaultContentFilters, filterPatterns)
+	}
+	contentFilters, err := CompileContentFilters(filterPatterns)
+	if err != nil {
+		log.Fatalln("Invalid content filter:", err)
+	}
+
+	if !slices.Contains([]string{toolCallContentKeep, toolCallContentDiscard, toolCallContentHide}, config.ToolCallContent) {
+		log.Fatalf("Invalid -tool-call-content %q (expected keep, discard, or hide)", config.ToolCallContent)
+	}
+
+	if config.OversizedInput != oversizedInputWarn && config.OversizedInput != oversizedInputRefuse {
+		log.Fatalf("Invalid -oversized-input %q (expected warn or refuse)", config.OversizedInput)
+	}
+
+	trimStrategy, err := parseTrimStrategy(config.Trim)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if config.NoThink {
+		if config.Think != "" && config.Think != "off" {
+			log.Fatalln("Use either -no-think or -think, not both.")
+		}
+		config.Think = "off"
+	}
+	think, err := parseThink(config.Think)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	keepAlive, err := parseKeepAlive(config.KeepAlive)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	sandbox, err := tools.NewSandbox(config.Root)
+	if err != nil {
+		log.Fatalln("Invalid -root:", err)
+	}
+
+	slashCommands, err := LoadSlashCommands(config.SlashCommands)
+	if err != nil {
+		log.Fatalln("Unable to load slash commands:", err)
+	}
+
+	events, eventsFile, err := newEventLogger(config.LogFormat, config.LogFile)
+	if err != nil {
+		log.Fatalln("Unable to set up the event log:", err)
+	}
+	if eventsFile != nil {
+		defer func() { _ = eventsFile.Close() }()
+	}
+
+	httpClient := newHTTPClient(config.MaxIdleConns, config.IdleConnTimeout, config.DisableKeepAlives)
+	var backend Backend
+	switch config.Backend {
+	case backendOllama:
+		ollama := NewOllamaBackend(cmp.Or(config.BackendURL, config.OllamaURL), httpClient)
+		ollama.KeepAlive = keepAlive
+		backend = ollama
+	case backendOpenAI:
+		backend = &OpenAIBackend{URL: cmp.Or(config.BackendURL, "http://localhost:8000"), APIKey: cmp.Or(config.APIKey, os.Getenv("OPENAI_API_KEY")), Client: httpClient}
+	case backendAnthropic:
+		apiKey := cmp.Or(config.APIKey, os.Getenv("ANTHROPIC_API_KEY"))
+		if apiKey == "" {
+			log.Fatalln("The anthropic backend needs -api-key or ANTHROPIC_API_KEY.")
+		}
+		backend = &AnthropicBackend{URL: cmp.Or(config.BackendURL, "https://api.anthropic.com"), APIKey: apiKey, Client: httpClient}
+	default:
+		log.Fatalf("Invalid -backend %q (expected ollama, openai, or anthropic)", config.Backend)
+	}
+	if config.Record != "" && config.Replay != "" {
+		log.Fatalln("Use either -record or -replay, not both.")
+	}
+	if config.Replay != "" {
+		backend, err = LoadReplayBackend(config.Replay)
+		if err != nil {
+			log.Fatalln("Unable to load the recording:", err)
+		}
+	}
+	if config.Record != "" {
+		recording, err := os.Create(config.Record)
+		if err != nil {
+			log.Fatalln("Unable to create the recording:", err)
+		}
+		defer func() { _ = recording.Close() }()
+		backend = NewRecordingBackend(backend, recording)
+	}
+	newAgent := func(model string) *Agent {
+		agent := NewAgent(model, config.OllamaURL)
+		agent.backend = backend
+		agent.events = events
+		agent.contentFilters = contentFilters
+		agent.think = think
+		agent.hideThinking = config.HideThinking
+		agent.options = config.Options
+		agent.systemPrompt = config.SystemPrompt
+		agent.instructionsPath = config.Instructions
+		agent.toolFailureLimit = config.ToolFailureLimit
+		agent.autoGofmt = config.AutoGofmt
+		agent.goimports = config.Goimports
+		agent.parseInlineToolCalls = config.ParseInlineToolCalls
+		agent.toolCallContent = config.ToolCallContent
+		agent.maxToolsInPrompt = config.MaxToolsInPrompt
+		agent.requestTimeout = config.RequestTimeout
+		agent.noStream = config.NoStream
+		agent.markdown = config.Markdown
+		agent.stats = config.Stats
+		agent.MaxIterations = config.MaxIterations
+		agent.ContextLimit = config.ContextLimit
+		agent.TrimStrategy = trimStrategy
+		agent.SummaryModel = config.SummaryModel
+		agent.coreTools = strings.Split(config.CoreTools, ",")
+		agent.approveAll = config.Yes
+		agent.sandbox = sandbox
+		agent.Reset()
+		return agent
+	}
 
-	agent := NewAgent(config.Model, config.OllamaURL)
-	agent.RegisterTool(&tools.ReadFileTool{})
-	agent.RegisterTool(&tools.WriteFileTool{})
-	agent.RegisterTool(&tools.ModifyFileTool{})
-	agent.RegisterTool(&tools.ReadAllFilesInDirectoryTool{})
-	agent.RegisterTool(&tools.RunCommandTool{})
-	agent.RegisterTool(&tools.ExecutePythonTool{})
+	if config.Compare != "" {
+		fmt.Print("You: ")
+		message, _ := readMessage()
+		compareModels(strings.Split(config.Compare, ","), message, newAgent)
+		return
+	}
 
+	agent := newAgent(config.Model)
+	agent.sessionsDir = config.SessionsDir
+	agent.autosaving = config.Autosave
+	if config.Resume {
+		name, messages, err := agent.Resume()
+		switch {
+		case err != nil:
+			log.Println("Unable to resume session:", err)
+		case name == "":
+			log.Println("No saved sessions to resume; starting fresh.")
+		default:
+			log.Printf("Resumed session %q (%d messages).", name, messages)
+		}
+	}
+	err = agent.RegisterTools(builtinTools(config)...)
+	if err != nil {
+		log.Fatalln("Unable to register tools:", err)
+	}
+
+	if config.Prompt != "" {
+		prompt, err := readPrompt(config.Prompt)
+		if err != nil {
+			log.Fatalln("Unable to read the prompt:", err)
+		}
+		ok, notice := checkInputSize(prompt, config.MaxInputBytes, config.OversizedInput)
+		if notice != "" {
+			fmt.Println(notice)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		agent.denyAll = true
+		if err := agent.ProcessMessage(prompt); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	interrupts := handleInterrupts()
 	for {
 		fmt.Println(strings.Repeat("#", 80))
 
 		fmt.Print("You: ")
-		input := readInput()
+		input, err := readMessage()
+		if err != nil {
+			fmt.Println("\nGoodbye!") // end of input
+			break
+		}
 		if input == "" {
 			continue
 		}
@@ -66,52 +376,347 @@ func main() {
 		}
 
 		if input == "clear" {
-			agent.conversation = agent.conversation[:0]
+			agent.Reset()
 			fmt.Println("Conversation history cleared.")
 			continue
 		}
 
-		if err := agent.ProcessMessage(input); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		if input == "reload" {
+			agent.ReloadPreamble()
+			if agent.preambleLen > 0 {
+				fmt.Println("Reloaded project instructions from", config.Instructions)
+			} else {
+				fmt.Println("No project instructions found at", config.Instructions)
+			}
+			continue
+		}
+
+		if input == "model" || strings.HasPrefix(input, "model ") {
+			if name := strings.TrimSpace(strings.TrimPrefix(input, "model")); name != "" {
+				if warning := agent.SwitchModel(name); warning != "" {
+					fmt.Println("Warning:", warning)
+				}
+				fmt.Printf("Switched to model %q; the conversation continues.\n", name)
+			} else {
+				fmt.Println("Current model:", agent.model)
+			}
+			continue
+		}
+
+		if input == "tools" || input == "/tools" {
+			agent.printTools()
+			continue
+		}
+
+		if input == "undo" || input == "/undo" {
+			description, err := agent.Undo()
+			if err != nil {
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Println("Undone:", description)
+			}
+			continue
+		}
+
+		if input == "extract-code" {
+			agent.ExtractCode()
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(input, "save "); ok {
+			if err := agent.SaveSession(strings.TrimSpace(name)); err != nil {
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Printf("Saved session %q.\n", agent.sessionName)
+			}
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(input, "load "); ok {
+			messages, err := agent.LoadSession(strings.TrimSpace(name))
+			if err != nil {
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Printf("Loaded session %q (%d messages).\n", agent.sessionName, messages)
+			}
+			continue
+		}
+
+		if command, ok := strings.CutPrefix(input, "run-tool "); ok {
+			name, arguments, _ := strings.Cut(strings.TrimSpace(command), " ")
+			ctx, done := interrupts.turn()
+			result, err := agent.RunTool(ctx, name, arguments)
+			done()
+			if err != nil {
+				fmt.Println("Error:", err)
+			}
+			fmt.Println(result)
+			continue
+		}
+
+		if query, ok := strings.CutPrefix(input, "search "); ok {
+			agent.Search(strings.TrimSpace(query))
+			continue
+		}
+
+		if strings.HasPrefix(input, "/") {
+			expanded, err := slashCommands.Expand(input)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			input = expanded
+		}
+
+		ok, notice := checkInputSize(input, config.MaxInputBytes, config.OversizedInput)
+		if notice != "" {
+			fmt.Println(notice)
+		}
+		if !ok {
+			continue
+		}
+
+		ctx, done := interrupts.turn()
+		err = agent.ProcessMessageContext(ctx, input)
+		done()
+		if errors.Is(err, errInterrupted) {
+			fmt.Println("Turn interrupted; the conversation continues.")
+		} else if err != nil && !errors.Is(err, ErrMaxIterations) {
+			fmt.Println(pretty.Colorize(pretty.RoleError, fmt.Sprintf("Error: %v", err)))
 		}
 
 		fmt.Println()
 	}
 }
 
+// builtinTools returns the tools every agent is given, configured from config.
+func builtinTools(config Config) []Tool {
+	var allowedHosts []string
+	if config.AllowedHosts != "" {
+		allowedHosts = strings.Split(config.AllowedHosts, ",")
+	}
+	return []Tool{
+		&tools.ReadFileTool{},
+		&tools.WriteFileTool{CreateDirs: config.AutoMkdir},
+		&tools.ModifyFileTool{},
+		&tools.ApplyPatchTool{},
+		&tools.FileOpsTool{},
+		&tools.ReadAllFilesInDirectoryTool{},
+		&tools.RunCommandTool{Output: os.Stdout},
+		&tools.ExecutePythonTool{},
+		&tools.ListeningPortsTool{},
+		&tools.EnvFileTool{},
+		&tools.GitResetTool{},
+		&tools.NormalizeFileTool{},
+		&tools.CommandHelpTool{},
+		&tools.GitInfoTool{},
+		&tools.GitTool{},
+		&tools.LogSummaryTool{},
+		&tools.StreamEditTool{},
+		&tools.DiffAgainstLastWriteTool{},
+		&tools.StructuralSearchTool{},
+		&tools.GrepTool{},
+		&tools.FindFilesTool{},
+		&tools.FetchURLTool{AllowedHosts: allowedHosts},
+		&tools.GenerateTestStubTool{},
+		&tools.GoDocTool{},
+		&tools.GoSymbolTool{},
+		&tools.CoverageTool{},
+		&tools.ApplyCodemodTool{},
+		&tools.ProfileCommandTool{},
+		&tools.LintConfigTool{},
+		&tools.ReadClipboardTool{},
+		&tools.WriteClipboardTool{},
+		&tools.OverviewTool{},
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 // Tool interface that all tools must implement
 type Tool interface {
 	Name() string
 	Description() string
-	Parameters() map[string]interface{}
-	Execute(params map[string]interface{}) (string, error)
+	Parameters() map[string]interface{}                                                   // JSON schema; calls are validated against it, so "required" must list what Execute needs
+	Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) // ctx ends with the turn
 	RequiresPermission() bool
 }
 
+// ConditionalPermission is implemented by tools that only require permission
+// for some invocations (e.g. writes but not reads). It takes precedence over
+// RequiresPermission.
+type ConditionalPermission interface {
+	RequiresPermissionFor(params map[string]interface{}) bool
+}
+
+func requiresPermission(tool Tool, params map[string]interface{}) bool {
+	if conditional, ok := tool.(ConditionalPermission); ok {
+		return conditional.RequiresPermissionFor(params)
+	}
+	return tool.RequiresPermission()
+}
+
+// PermissionWarner is implemented by tools whose effects deserve an explicit
+// warning in the permission prompt. An empty warning is not shown.
+type PermissionWarner interface {
+	PermissionWarning(params map[string]interface{}) string
+}
+
+// ReplaySafe is implemented by tools whose operations must not be applied twice
+// in a single turn (e.g. appends re-issued after a retry). ReplayKey returns the
+// path affected and a key describing the operation and its content.
+type ReplaySafe interface {
+	ReplayKey(params map[string]interface{}) (path, key string)
+}
+
+// Previewer is implemented by tools that can show a side-effect-free preview
+// (e.g. a dry run) of a call while the user decides whether to approve it. ok
+// is false when the particular call has no safe preview.
+type Previewer interface {
+	DryRunPreview(params map[string]interface{}) (preview string, ok bool)
+}
+
 // Agent manages the conversation and tool execution
 type Agent struct {
-	model        string
-	ollamaURL    string
-	tools        map[string]Tool
-	conversation []Message
+	// OnToken, if set, receives the response text as it streams in, e.g. to
+	// render it somewhere other than the terminal.
+	OnToken TokenHandler
+	// TerminalOutput prints streamed responses to stdout (the default).
+	TerminalOutput bool
+	// MaxIterations limits the model requests made for one user message (0 means the default of 10).
+	MaxIterations int
+	// ContextLimit is the model's context window in (estimated) tokens; 0 disables the check.
+	ContextLimit int
+	// TrimStrategy says what to do when the conversation exceeds ContextLimit.
+	TrimStrategy TrimStrategy
+	// SummaryModel writes summaries for TrimSummarize (empty uses the agent's model).
+	SummaryModel string
+
+	model          string
+	backend        Backend
+	events         *slog.Logger // structured event log (see newEventLogger)
+	tools          map[string]Tool
+	conversation   []Message
+	contentFilters []*regexp.Regexp
+	think          interface{}
+	hideThinking   bool                   // don't display thinking as it streams
+	options        map[string]interface{} // model options (see modelOption)
+	session        *session
+	sandbox        tools.Sandbox // root injected into SandboxAware tools (zero value: unrestricted)
+
+	systemPrompt     string
+	instructionsPath string
+	preambleLen      int // number of system messages at the start of conversation
+
+	toolFailureLimit int
+	toolFailures     map[string]int // consecutive failures per tool
+
+	autoGofmt bool
+	goimports bool
+
+	parseInlineToolCalls bool   // fall back to tool calls written as JSON in the content
+	toolCallContent      string // what to do with content accompanying tool calls: keep, discard, or hide
+
+	requestTimeout time.Duration // limit on each model request, including its streamed response (0 for none)
+	noStream       bool          // request whole responses instead of streaming them
+	markdown       bool          // render replies as markdown once complete (so content isn't streamed)
+	stats          bool          // print turnUsage after each turn
+	turnUsage      Usage         // summed over the current turn's responses
+
+	maxToolsInPrompt int      // 0 sends every tool definition
+	coreTools        []string // tools always sent when maxToolsInPrompt applies
+
+	approveAll  bool // -yes: approve permission-gated tools without asking, even after Reset
+	autoApprove bool // approve without asking for the rest of this conversation
+	denyAll     bool // there is no one to ask (-prompt): deny what isn't approved automatically
+
+	sessionsDir string // where conversations are saved ("" disables saving)
+	autosaving  bool   // save the conversation after every turn (-autosave)
+	sessionName string // file (without extension) the conversation is saved to
+
+	// appliedThisTurn maps a path to the hash of the last ReplaySafe operation applied to it during the current turn,
+	// along with the hash of the file it left behind.
+	appliedThisTurn map[string]string
+	// deniedThisTurn counts permission denials per tool call (name and arguments) during the current turn.
+	deniedThisTurn map[string]int
 }
 
 func NewAgent(model, ollamaURL string) *Agent {
 	return &Agent{
-		model:     model,
-		ollamaURL: ollamaURL,
-		tools:     make(map[string]Tool),
+		model:   model,
+		backend: NewOllamaBackend(ollamaURL, newHTTPClient(defaultMaxIdleConns, defaultIdleConnTimeout, false)),
+		tools:   make(map[string]Tool),
+		session: newSession(),
+		events:  slog.New(slog.DiscardHandler),
+
+		appliedThisTurn: make(map[string]string),
+		deniedThisTurn:  make(map[string]int),
+		toolFailures:    make(map[string]int),
+		toolCallContent: toolCallContentKeep,
+		TerminalOutput:  true,
 	}
 }
 
-func (this *Agent) RegisterTool(tool Tool) {
+// RegisterTool makes tool available to the model. A tool whose name is
+// already registered is refused with an error rather than replacing the first.
+func (this *Agent) RegisterTool(tool Tool) error {
+	if _, ok := this.tools[tool.Name()]; ok {
+		return fmt.Errorf("a tool named %q is already registered", tool.Name())
+	}
+	if aware, ok := tool.(tools.ContextAware); ok {
+		aware.SetSession(this.session)
+	}
+	if aware, ok := tool.(tools.SandboxAware); ok {
+		aware.SetSandbox(this.sandbox)
+	}
 	this.tools[tool.Name()] = tool
+	return nil
+}
+
+// RegisterTools registers each tool in turn, stopping at the first error.
+func (this *Agent) RegisterTools(list ...Tool) error {
+	for _, tool := range list {
+		if err := this.RegisterTool(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTools returns the registered tools, sorted by name.
+func (this *Agent) ListTools() []Tool {
+	results := slices.Collect(maps.Values(this.tools))
+	slices.SortFunc(results, func(a, b Tool) int { return strings.Compare(a.Name(), b.Name()) })
+	return results
+}
+
+// printTools lists the registered tools and whether they ask before running.
+func (this *Agent) printTools() {
+	for _, tool := range this.ListTools() {
+		permission := ""
+		if _, ok := tool.(ConditionalPermission); ok {
+			permission = " (asks permission for some calls)"
+		} else if tool.RequiresPermission() {
+			permission = " (asks permission)"
+		}
+		fmt.Printf("%s%s\n    %s\n", tool.Name(), permission, tool.Description())
+	}
 }
 
-func (this *Agent) getToolDefinitions() (results []ToolCall) {
-	for _, tool := range this.tools {
+// lastUserMessage returns the content of the most recent user message.
+func (this *Agent) lastUserMessage() string {
+	for i := len(this.conversation) - 1; i >= 0; i-- {
+		if this.conversation[i].Role == "user" {
+			return this.conversation[i].Content
+		}
+	}
+	return ""
+}
+
+// getToolDefinitions describes the tools offered to the model for the given
+// user message (see selectTools).
+func (this *Agent) getToolDefinitions(message string) (results []ToolCall) {
+	for _, tool := range selectTools(this.tools, message, this.maxToolsInPrompt, this.coreTools) {
 		results = append(results, ToolCall{
 			Type: "function",
 			Function: ToolFunction{
@@ -124,214 +729,544 @@ func (this *Agent) getToolDefinitions() (results []ToolCall) {
 	return results
 }
 
-func (this *Agent) askPermission(toolName string, params map[string]interface{}) bool {
-	fmt.Println(strings.Repeat("#", 80))
-	fmt.Printf("\n⚠️  The AI wants to execute: %s\n", toolName)
-	fmt.Println("Parameters:")
-	for k, v := range params {
-		fmt.Printf("  %s: %v\n", k, v)
+// askPermission prompts the user to allow the tool call. The user may also edit
+// the arguments first, in which case the edited arguments are returned.
+// Once the user answers "all" (or with -yes), later calls are approved
+// without asking until the conversation is cleared.
+func (this *Agent) askPermission(tool Tool, params map[string]interface{}) (bool, map[string]interface{}) {
+	if this.autoApprove {
+		fmt.Printf("\n✅ Auto-approved: %s\n", tool.Name())
+		return true, params
+	}
+	if this.denyAll {
+		fmt.Printf("\n🚫 Denied: %s (not running interactively; use -yes to allow)\n", tool.Name())
+		return false, params
+	}
+	for {
+		fmt.Println(strings.Repeat("#", 80))
+		fmt.Printf("\n⚠️  The AI wants to execute: %s\n", tool.Name())
+		fmt.Println("Parameters:")
+		for k, v := range params {
+			fmt.Printf("  %s: %v\n", k, v)
+		}
+		if warner, ok := tool.(PermissionWarner); ok {
+			if warning := warner.PermissionWarning(params); warning != "" {
+				fmt.Printf("\n🚨 %s\n\n", warning)
+			}
+		}
+		if previewer, ok := tool.(Previewer); ok {
+			if preview, ok := previewer.DryRunPreview(params); ok {
+				fmt.Println("\nDry-run preview:")
+				fmt.Println(strings.TrimRight(preview, "\n"))
+				fmt.Println()
+			}
+		}
+		fmt.Print("Allow? (Y/n, a=allow all for this session, e=edit arguments): ")
+		response := strings.TrimSpace(strings.ToLower(readInput()))
+		switch response {
+		case "", "y", "yes":
+			return true, params
+		case "a", "all":
+			this.autoApprove = true
+			fmt.Println("Tools will run without asking until the conversation is cleared.")
+			return true, params
+		case "e", "edit", "edit-args":
+			if edited, ok := editArguments(params); ok {
+				params = edited
+			}
+		default:
+			return false, params
+		}
 	}
-	fmt.Print("Allow? (Y/n): ")
-	response := strings.TrimSpace(strings.ToLower(readInput()))
-	return response == "" || response == "y" || response == "yes"
 }
 
+// defaultMaxIterations is the number of model requests a single user message may trigger unless Agent.MaxIterations says otherwise.
+const defaultMaxIterations = 10
+
+// ErrMaxIterations is returned by ProcessMessage when the agentic loop was cut
+// off at MaxIterations while the model was still calling tools.
+var ErrMaxIterations = errors.New("reached max iterations before the agent finished")
+
+// ProcessMessage sends the user's message and runs the agentic loop until the
+// model stops calling tools (or ErrMaxIterations).
 func (this *Agent) ProcessMessage(userMessage string) error {
+	return this.ProcessMessageContext(context.Background(), userMessage)
+}
+
+// ProcessMessageContext is ProcessMessage with a context; canceling it ends the
+// turn promptly, even in the middle of a streamed response.
+func (this *Agent) ProcessMessageContext(ctx context.Context, userMessage string) error {
 	this.conversation = append(this.conversation, Message{
 		Role:    "user",
 		Content: userMessage,
 	})
+	this.appliedThisTurn = make(map[string]string)
+	this.deniedThisTurn = make(map[string]int)
+	this.turnUsage = Usage{}
+	defer this.autosave()
+	defer this.printStats()
 
 	// Agentic loop: continue making requests as long as tools are being called
-	maxIterations := 10
+	maxIterations := cmp.Or(this.MaxIterations, defaultMaxIterations)
 	for iteration := 0; iteration < maxIterations; iteration++ {
-		shouldContinue, err := this.processOneResponse()
+		this.fitContext(ctx)
+		shouldContinue, err := this.processOneResponse(ctx)
 		if err != nil {
+			this.events.Error(eventError, "error", err.Error())
 			return err
 		}
 		if !shouldContinue {
-			break
+			return nil
+		}
+		if iteration+1 < maxIterations {
+			fmt.Printf("\n[Continuing agentic loop, iteration %d/%d]\n", iteration+2, maxIterations)
 		}
-		fmt.Printf("\n[Continuing agentic loop, iteration %d/%d]\n", iteration+2, maxIterations)
 	}
-	return nil
+	fmt.Printf("\n⚠️  Reached max iterations (%d); the agent may not have finished.\n", maxIterations)
+	this.events.Error(eventError, "error", ErrMaxIterations.Error(), "iterations", maxIterations)
+	return ErrMaxIterations
 }
 
-func (this *Agent) processOneResponse() (shouldContinue bool, err error) {
-	// Start spinner while waiting for response
-	spinner := pretty.NewSpinner("Waiting for response...")
-	spinner.Start()
-	defer spinner.Stop()
-
-	req := OllamaRequest{
-		Model:    this.model,
-		Messages: this.conversation,
-		Stream:   true,
-		Tools:    this.getToolDefinitions(),
+func (this *Agent) processOneResponse(ctx context.Context) (shouldContinue bool, err error) {
+	// -request-timeout limits the model request and its response; the
+	// permission prompts and tools that follow run under the turn's ctx.
+	requestCtx := ctx
+	if this.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, this.requestTimeout)
+		defer cancel()
 	}
 
-	jsonData, err := json.MarshalIndent(req, "", "  ")
-	if err != nil {
-		return false, err
+	// Start spinner while waiting for response (its escape codes would only clutter non-streamed output)
+	spinner := pretty.NewSpinner("Thinking…")
+	if !this.noStream {
+		spinner.Start()
 	}
+	defer spinner.Stop()
 
-	// TODO: implement retry
-	request, err := http.NewRequest("POST", this.ollamaURL+"/api/chat", bytes.NewReader(jsonData))
-	if err != nil {
-		return false, err
+	request := ChatRequest{
+		Model:    this.model,
+		Messages: this.conversation,
+		Stream:   !this.noStream,
+		Tools:    this.getToolDefinitions(this.lastUserMessage()),
+		Think:    this.think,
+		Options:  this.options,
 	}
-	request.Header.Set("Content-Type", "application/json")
-
-	// requestDump, err := httputil.DumpRequestOut(request, false)
-	// if err != nil {
-	// 	return false, err
-	// }
-	// fmt.Println(strings.Repeat("#", 80))
-	// log.Printf("Request dump:\n%s\n\n%s", requestDump, jsonData)
-
-	response, err := http.DefaultClient.Do(request)
+	started := time.Now()
+	this.events.Info(eventRequestSent, "model", request.Model, "messages", len(request.Messages), "tools", len(request.Tools), "stream", request.Stream)
+	stream, err := this.backend.Chat(requestCtx, request)
 	if err != nil {
+		if requestCtx.Err() != nil {
+			return false, fmt.Errorf("request canceled: %w", context.Cause(requestCtx))
+		}
 		return false, err
 	}
-	defer func() { _ = response.Body.Close() }()
+	defer func() { _ = stream.Close() }()
+	stopWatching := closeOnDone(requestCtx, stream)
+	defer stopWatching()
 
-	// Handle streaming response
-	scanner := bufio.NewScanner(response.Body)
+	// Handle the response; when not streaming it arrives as a single chunk
 	var finalMessage Message
-	var thinkingDisplayed bool
-	var contentDisplayed bool
+	emit := this.tokenHandler()
+	filter := NewStreamFilter(this.contentFilters)
+	deferContent := this.toolCallContent == toolCallContentHide || this.markdown
 
-	for scanner.Scan() {
-		spinner.Stop()
-
-		line := scanner.Text()
-		if line == "" {
-			continue
+	for {
+		chunk, err := stream.Next()
+		if err != nil || chunk.Thinking != "" || chunk.Content != "" || len(chunk.ToolCalls) > 0 {
+			spinner.Stop() // keep it up through empty chunks (role only, keep-alives) until there is something to show
 		}
-
-		var chunk OllamaResponse
-		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
-			log.Printf("Error parsing chunk: %v\n", err)
-			continue
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil {
+			this.events.Debug(eventChunkReceived, "elapsed", time.Since(started),
+				"thinking_bytes", len(chunk.Thinking), "content_bytes", len(chunk.Content), "tool_calls", len(chunk.ToolCalls))
+		}
+		if err != nil {
+			stopWatching()
+			if requestCtx.Err() != nil {
+				fmt.Println()
+				this.keepInterrupted(requestCtx, finalMessage)
+				return false, fmt.Errorf("response canceled: %w", context.Cause(requestCtx))
+			}
+			return false, fmt.Errorf("error reading stream: %v", err)
 		}
 
 		// Display thinking if present
-		if chunk.Message.Thinking != "" {
-			if !thinkingDisplayed {
-				fmt.Print("\n💭 Thinking: ")
-				thinkingDisplayed = true
+		if chunk.Thinking != "" && this.think != false {
+			if !this.hideThinking {
+				emit(tokenThinking, chunk.Thinking)
 			}
-			fmt.Print(chunk.Message.Thinking)
-			finalMessage.Thinking += chunk.Message.Thinking
+			finalMessage.Thinking += chunk.Thinking
 		}
 
 		// Display content if present
-		if chunk.Message.Content != "" {
-			if !contentDisplayed {
-				if thinkingDisplayed {
-					fmt.Println() // New line after thinking
-				}
-				fmt.Print("\n🤖 Assistant: ")
-				contentDisplayed = true
-			}
-			fmt.Print(chunk.Message.Content)
-			finalMessage.Content += chunk.Message.Content
+		if content := filter.Write(chunk.Content); content != "" && deferContent {
+			finalMessage.Content += content // shown after the stream (for hide, only if no tools are called)
+		} else if content != "" {
+			emit(tokenContent, content)
+			finalMessage.Content += content
 		}
 
 		// Accumulate other fields
-		if chunk.Message.Role != "" {
-			finalMessage.Role = chunk.Message.Role
+		if chunk.Usage != nil {
+			this.turnUsage.add(*chunk.Usage)
 		}
-		if len(chunk.Message.ToolCalls) > 0 {
-			finalMessage.ToolCalls = chunk.Message.ToolCalls
+		if chunk.Role != "" {
+			finalMessage.Role = chunk.Role
 		}
+		finalMessage.ToolCalls = append(finalMessage.ToolCalls, chunk.ToolCalls...)
+	}
 
-		if chunk.Done {
-			break
+	stopWatching()
+	if requestCtx.Err() != nil {
+		fmt.Println()
+		this.keepInterrupted(requestCtx, finalMessage)
+		return false, fmt.Errorf("response canceled: %w", context.Cause(requestCtx))
+	}
+	if finalMessage.Role == "" {
+		finalMessage.Role = "assistant"
+	}
+	this.events.Info(eventResponseReceived, "model", this.model, "duration", time.Since(started),
+		"thinking_bytes", len(finalMessage.Thinking), "content_bytes", len(finalMessage.Content), "tool_calls", len(finalMessage.ToolCalls))
+
+	if deferContent {
+		finalMessage.Content += filter.Flush()
+	}
+	if content := filter.Flush(); content != "" {
+		emit(tokenContent, content)
+		finalMessage.Content += content
+	}
+
+	if this.parseInlineToolCalls && len(finalMessage.ToolCalls) == 0 {
+		finalMessage.ToolCalls = parseInlineToolCalls(finalMessage.Content, this.tools)
+		if len(finalMessage.ToolCalls) > 0 {
+			log.Printf("🔎 Parsed %d tool call(s) from the response content", len(finalMessage.ToolCalls))
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		spinner.Stop()
-		return false, fmt.Errorf("error reading stream: %v", err)
+	if strings.TrimSpace(finalMessage.Content) == "" && len(finalMessage.ToolCalls) == 0 {
+		// Leave the empty reply out of the history; it only confuses later turns.
+		fmt.Println("\n⚠️  The model returned no content. Try rephrasing your message or using a different model.")
+		fmt.Println(strings.Repeat("#", 80))
+		return false, nil
+	}
+
+	if deferContent && (this.toolCallContent != toolCallContentHide || len(finalMessage.ToolCalls) == 0) {
+		emit(tokenContent, finalMessage.Content)
 	}
 
 	fmt.Println() // New line after output
 	fmt.Println(strings.Repeat("#", 80))
 
-	this.conversation = append(this.conversation, finalMessage)
+	// Backends that pair tool results with calls (OpenAI) need an ID on every
+	// call; models served by Ollama don't provide one.
+	for i := range finalMessage.ToolCalls {
+		if finalMessage.ToolCalls[i].ID == "" {
+			finalMessage.ToolCalls[i].ID = fmt.Sprintf("call_%d_%d", len(this.conversation), i)
+		}
+	}
+
+	stored := finalMessage
+	if len(stored.ToolCalls) > 0 && this.toolCallContent != toolCallContentKeep {
+		stored.Content = "" // leave text that accompanied tool calls out of the history
+	}
+	for i := range this.conversation {
+		this.conversation[i].Thinking = "" // only the latest response's reasoning is worth re-sending (and saving)
+	}
+	this.conversation = append(this.conversation, stored)
 
 	// Track tool execution for agentic loop
 	var toolsExecuted int
-	var anyToolRequiredPermission bool
+	var anyDenied bool
 
-	for _, toolCall := range finalMessage.ToolCalls {
+	for i, toolCall := range finalMessage.ToolCalls {
 		toolName := toolCall.Function.Name
+		params := toolCall.Function.Arguments
+		if ctx.Err() != nil {
+			// Answer the remaining calls so every call still has a result.
+			this.conversation = append(this.conversation, Message{
+				Role:       "tool",
+				Content:    fmt.Sprintf("%s was not run: %v.", toolName, context.Cause(ctx)),
+				ToolCallID: toolCall.ID,
+			})
+			continue
+		}
 		tool, exists := this.tools[toolName]
 		if !exists {
 			log.Println("🤖 response refers to unknown tool:", toolName)
+			this.conversation = append(this.conversation, Message{
+				Role:       "tool",
+				Content:    fmt.Sprintf("Unknown tool %s", toolName),
+				ToolCallID: toolCall.ID,
+			})
+			continue
+		}
+
+		if this.toolFailureLimit > 0 && this.toolFailures[toolName] >= this.toolFailureLimit {
+			log.Printf("🔌 Circuit open for %s after %d consecutive failures", toolName, this.toolFailures[toolName])
+			this.conversation = append(this.conversation, Message{
+				Role: "tool",
+				Content: fmt.Sprintf("Tool %s is unavailable: it failed %d times in a row this session. "+
+					"Do not call it again; use a different approach.", toolName, this.toolFailures[toolName]),
+				ToolCallID: toolCall.ID,
+			})
+			continue
+		}
+
+		// Reject malformed arguments before asking for permission, so the model can correct them.
+		if err := tools.ValidateParams(tool.Parameters(), params); err != nil {
+			this.toolFailures[toolName]++
+			this.events.Error(eventToolResult, "tool", toolName, "call_id", toolCall.ID, "is_error", true, "error", err.Error())
+			fmt.Println(pretty.Colorize(pretty.RoleError, fmt.Sprintf("🧾 %s was called with %v", toolName, err)))
+			this.conversation = append(this.conversation, Message{
+				Role:       "tool",
+				Content:    fmt.Sprintf("Error: %v", err),
+				ToolCallID: toolCall.ID,
+			})
+			toolsExecuted++ // let the model react to the error
 			continue
 		}
 
 		// Check if permission is required
-		if tool.RequiresPermission() {
-			anyToolRequiredPermission = true
-			if !this.askPermission(toolName, toolCall.Function.Arguments) {
+		if requiresPermission(tool, params) {
+			allowed, edited := this.askPermission(tool, params)
+			// Edited arguments replace the originals in the stored assistant message so the model sees what actually ran.
+			finalMessage.ToolCalls[i].Function.Arguments = edited
+			params = edited
+			if !allowed {
+				anyDenied = true
 				this.conversation = append(this.conversation, Message{
-					Role:    "tool",
-					Content: fmt.Sprintf("Permission denied for %s", toolName),
+					Role:       "tool",
+					Content:    fmt.Sprintf("Permission denied for %s", toolName),
+					ToolCallID: toolCall.ID,
 				})
+				if this.recordDenial(toolName, params) >= maxRepeatedDenials {
+					fmt.Printf("\n🛑 %s was denied %d times with the same arguments; ending this turn.\n", toolName, maxRepeatedDenials)
+					fmt.Println("Try rephrasing your request or adjusting what you're willing to allow.")
+					for _, remaining := range finalMessage.ToolCalls[i+1:] {
+						this.conversation = append(this.conversation, Message{
+							Role:       "tool",
+							Content:    fmt.Sprintf("%s was not run: the turn ended after repeated denials.", remaining.Function.Name),
+							ToolCallID: remaining.ID,
+						})
+					}
+					return false, nil
+				}
 				continue
 			}
 		}
 
 		fmt.Println(strings.Repeat("#", 80))
 		fmt.Printf("🔧 Executing tool: %s\n", toolName)
-		result, err := tool.Execute(toolCall.Function.Arguments)
+		this.events.Info(eventToolCalled, "tool", toolName, "call_id", toolCall.ID, "arguments", params)
+		toolStarted := time.Now()
+		result, err := this.execute(ctx, tool, params)
+		this.logToolResult(toolName, toolCall.ID, time.Since(toolStarted), result, err)
+		resultRole := pretty.RoleTool
 		if err != nil {
-			result = fmt.Sprintf("Error: %v", err)
+			result.Content = fmt.Sprintf("Error: %v", err)
+			result.IsError = true
+			resultRole = pretty.RoleError
+			this.toolFailures[toolName]++
+		} else {
+			delete(this.toolFailures, toolName)
+			result = this.autoFormat(toolName, result)
 		}
 		fmt.Println(strings.Repeat("#", 80))
 		fmt.Println("## Result of tool call:", toolName)
 		fmt.Println()
-		fmt.Println(result)
+		fmt.Println(pretty.Colorize(resultRole, result.Content))
 		fmt.Println()
 		fmt.Println(strings.Repeat("#", 80))
 
 		this.conversation = append(this.conversation, Message{
-			Role:    "tool",
-			Content: result,
+			Role:       "tool",
+			Content:    result.Content,
+			ToolCallID: toolCall.ID,
 		})
 		toolsExecuted++
 	}
 
-	// Continue agentic loop if tools were executed and none required permission
-	shouldContinue = toolsExecuted > 0 && !anyToolRequiredPermission
+	if ctx.Err() != nil {
+		return false, fmt.Errorf("turn canceled: %w", context.Cause(ctx))
+	}
+
+	// Continue the agentic loop so the model can react to the tool results,
+	// unless the user denied a call: that hands the turn back to them.
+	shouldContinue = toolsExecuted > 0 && !anyDenied
 	return shouldContinue, nil
 }
 
+// logToolResult records a tool_result event (at error level when the tool
+// failed).
+func (this *Agent) logToolResult(toolName, callID string, duration time.Duration, result tools.ToolResult, err error) {
+	attributes := []interface{}{"tool", toolName, "call_id", callID, "duration", duration, "is_error", err != nil, "content_bytes", len(result.Content)}
+	if len(result.Metadata) > 0 {
+		attributes = append(attributes, "metadata", result.Metadata)
+	}
+	if err != nil {
+		this.events.Error(eventToolResult, append(attributes, "error", err.Error())...)
+		return
+	}
+	this.events.Info(eventToolResult, attributes...)
+}
+
+// keepInterrupted stores what arrived of a response that was cut short (its
+// text, not its incomplete tool calls) with a note, so the model knows it was
+// interrupted.
+func (this *Agent) keepInterrupted(ctx context.Context, partial Message) {
+	partial.Role = "assistant"
+	partial.ToolCalls = nil
+	partial.Content = strings.TrimSpace(partial.Content + fmt.Sprintf("\n\n[response cut short: %v]", context.Cause(ctx)))
+	this.conversation = append(this.conversation, partial)
+}
+
+// Values for -tool-call-content.
+const (
+	toolCallContentKeep    = "keep"
+	toolCallContentDiscard = "discard"
+	toolCallContentHide    = "hide"
+)
+
+// maxRepeatedDenials is how many times the same tool call may be denied in one
+// turn before the turn is ended instead of letting the model ask again.
+const maxRepeatedDenials = 2
+
+// recordDenial counts a denied tool call and returns how many times this exact
+// call (tool and arguments) has been denied during the current turn.
+func (this *Agent) recordDenial(toolName string, params map[string]interface{}) int {
+	arguments, _ := json.Marshal(params) // map keys are sorted, so equal arguments encode equally
+	key := toolName + "\x00" + string(arguments)
+	this.deniedThisTurn[key]++
+	return this.deniedThisTurn[key]
+}
+
+// execute runs the tool, skipping a ReplaySafe operation identical to the
+// last one applied to the same path during this turn, as long as the file is
+// still as that operation left it. Once anything else has changed the file
+// (another tool, an undo, an editor), repeating the operation is deliberate.
+func (this *Agent) execute(ctx context.Context, tool Tool, params map[string]interface{}) (tools.ToolResult, error) {
+	replaySafe, ok := tool.(ReplaySafe)
+	if !ok {
+		return tool.Execute(ctx, params)
+	}
+	path, key := replaySafe.ReplayKey(params)
+	sum := sha256.Sum256([]byte(tool.Name() + "\x00" + key))
+	hash := hex.EncodeToString(sum[:])
+	if this.appliedThisTurn[path] == hash+" "+fileHash(path) {
+		return tools.ToolResult{Content: fmt.Sprintf("%s on %s was already applied this turn; skipping.", tool.Name(), path)}, nil
+	}
+	result, err := tool.Execute(ctx, params)
+	if err == nil {
+		this.appliedThisTurn[path] = hash + " " + fileHash(path)
+	}
+	return result, err
+}
+
+// fileHash returns the hex SHA-256 of the file's content, or "" if it can't be read.
+func fileHash(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
-func readInput() string {
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return scanner.Text()
+// modelOption defines the flag name, which sets the Ollama model option key
+// (a whole number when integer is set). Options are only recorded when their
+// flag is given, so the model's own defaults apply to the rest.
+func modelOption(flags *flag.FlagSet, options map[string]interface{}, name, key string, integer bool) {
+	usage := fmt.Sprintf("Set the model option %s (ollama backend; unset uses the model default).", key)
+	flags.Func(name, usage, func(value string) error {
+		if integer {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.New("expected a whole number")
+			}
+			options[key] = number
+			return nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		options[key] = number
+		return nil
+	})
+}
+
+// parseKeepAlive converts the -keep-alive flag into the value of
+// OllamaRequest.KeepAlive: a duration string, or -1 (Ollama's "forever") for
+// a negative value. An empty value yields nil, so the field is omitted.
+func parseKeepAlive(value string) (interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		value += "s"
+		if seconds < 0 {
+			return -1, nil
+		}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -keep-alive value %q (expected a duration such as 5m, or -1)", value)
+	}
+	if duration < 0 {
+		return -1, nil
+	}
+	return duration.String(), nil
+}
+
+// parseThink converts the -think flag into the value of ChatRequest.Think
+// (nil when unset, so the field is omitted).
+func parseThink(value string) (interface{}, error) {
+	switch strings.ToLower(value) {
+	case "":
+		return nil, nil
+	case "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	case "low", "medium", "high":
+		return strings.ToLower(value), nil
+	default:
+		return nil, fmt.Errorf("invalid -think value %q (expected on, off, low, medium, or high)", value)
+	}
+}
+
+// readPrompt returns the -prompt value, or all of stdin for "-".
+func readPrompt(value string) (string, error) {
+	if value != "-" {
+		return value, nil
+	}
+	input, err := io.ReadAll(os.Stdin)
+	return strings.TrimSpace(string(input)), err
 }
 
 // Message represents a chat message
 type Message struct {
-	Role      string     `json:"role"`
-	Content   string     `json:"content"`
-	Thinking  string     `json:"thinking,omitempty"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	Thinking   string     `json:"thinking,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"` // on tool results: the ToolCall.ID answered
 }
 
 // OllamaRequest represents the request to Ollama API
 type OllamaRequest struct {
-	Model    string     `json:"model,omitempty"`
-	Stream   bool       `json:"stream"` // TODO: rework to utilize streaming (and visualize 'thinking' vs 'content'
-	Tools    []ToolCall `json:"tools,omitempty"`
-	Messages []Message  `json:"messages,omitempty"`
+	Model     string                 `json:"model,omitempty"`
+	Stream    bool                   `json:"stream"` // TODO: rework to utilize streaming (and visualize 'thinking' vs 'content'
+	Tools     []ToolCall             `json:"tools,omitempty"`
+	Messages  []Message              `json:"messages,omitempty"`
+	Think     interface{}            `json:"think,omitempty"` // bool, or a level string ("low", "medium", "high")
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive interface{}            `json:"keep_alive,omitempty"` // a duration string, or -1 to keep the model loaded
 }
 
 // OllamaResponse represents the response from Ollama API
@@ -340,10 +1275,19 @@ type OllamaResponse struct {
 	CreatedAt string  `json:"created_at,omitempty"`
 	Message   Message `json:"message,omitempty"`
 	Done      bool    `json:"done,omitempty"`
+
+	// Reported on the final response; durations are in nanoseconds.
+	TotalDuration      int64 `json:"total_duration,omitempty"`
+	LoadDuration       int64 `json:"load_duration,omitempty"`
+	PromptEvalCount    int   `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int   `json:"eval_count,omitempty"`
+	EvalDuration       int64 `json:"eval_duration,omitempty"`
 }
 
 // ToolCall represents a tool call in the message
 type ToolCall struct {
+	ID       string       `json:"id,omitempty"`
 	Type     string       `json:"type,omitempty"`
 	Function ToolFunction `json:"function,omitempty"`
 }
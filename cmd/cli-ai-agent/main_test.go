@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestThinkSerialization(t *testing.T) {
+	cases := []struct {
+		flag string
+		want string // the think field in the request, or "" for none
+	}{
+		{flag: "", want: ""},
+		{flag: "on", want: `"think":true`},
+		{flag: "off", want: `"think":false`},
+		{flag: "High", want: `"think":"high"`},
+	}
+	for _, test := range cases {
+		t.Run(test.flag, func(t *testing.T) {
+			think, err := parseThink(test.flag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := json.Marshal(OllamaRequest{Model: "m", Think: think})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.want == "" {
+				if strings.Contains(string(body), `"think"`) {
+					t.Errorf("think wasn't omitted: %s", body)
+				}
+			} else if !strings.Contains(string(body), test.want) {
+				t.Errorf("%s doesn't contain %s", body, test.want)
+			}
+		})
+	}
+	if _, err := parseThink("extreme"); err == nil {
+		t.Error("expected an invalid level to be refused")
+	}
+}
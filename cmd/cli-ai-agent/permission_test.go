@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// previewTool needs permission and offers a canned dry-run preview.
+type previewTool struct {
+	ran bool
+}
+
+func (this *previewTool) Name() string        { return "cleanup" }
+func (this *previewTool) Description() string { return "Deletes build output." }
+func (this *previewTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (this *previewTool) RequiresPermission() bool { return true }
+func (this *previewTool) DryRunPreview(params map[string]interface{}) (string, bool) {
+	return "would remove build/app\nwould remove build/app.o\n", true
+}
+func (this *previewTool) Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) {
+	this.ran = true
+	return tools.ToolResult{Content: "removed"}, nil
+}
+
+func TestDryRunPreviewIsShownBeforeApproval(t *testing.T) {
+	tool := &previewTool{}
+	agent := newTestAgent(t, tool)
+	useInput(t, "n\n")
+
+	var allowed bool
+	output := captureStdout(t, func() {
+		allowed, _ = agent.askPermission(tool, map[string]interface{}{})
+	})
+	if allowed {
+		t.Error("the call was allowed after answering no")
+	}
+	preview := strings.Index(output, "Dry-run preview:\nwould remove build/app\nwould remove build/app.o\n")
+	prompt := strings.Index(output, "Allow?")
+	if preview < 0 || prompt < preview {
+		t.Errorf("the preview should come before the question:\n%s", output)
+	}
+	if tool.ran {
+		t.Error("asking for permission ran the tool")
+	}
+}
+
+func TestRepeatedDenialEndsTurn(t *testing.T) {
+	tool := &previewTool{}
+	agent := newTestAgent(t, tool)
+	call := callTool("cleanup", map[string]interface{}{})[0].ToolCalls[0]
+	backend := &scriptedBackend{responses: [][]ChatChunk{
+		{{Role: "assistant", ToolCalls: []ToolCall{call, call, call}}},
+		reply("Should not be requested."),
+	}}
+	agent.backend = backend
+	useInput(t, "n\nn\nn\n")
+
+	output := captureStdout(t, func() {
+		if err := agent.ProcessMessage("clean up"); err != nil {
+			t.Error(err)
+		}
+	})
+	if asked := strings.Count(output, "Allow?"); asked != 2 {
+		t.Errorf("asked %d times, want 2", asked)
+	}
+	if !strings.Contains(output, "cleanup was denied 2 times with the same arguments; ending this turn.") {
+		t.Errorf("no explanation in:\n%s", output)
+	}
+	if tool.ran || len(backend.requests) != 1 {
+		t.Errorf("the turn went on: ran %v, %d requests", tool.ran, len(backend.requests))
+	}
+	answered := map[string]bool{}
+	for _, message := range agent.conversation {
+		if message.Role == "tool" {
+			answered[message.ToolCallID] = true
+		}
+	}
+	for _, message := range agent.conversation {
+		for _, call := range message.ToolCalls {
+			if !answered[call.ID] {
+				t.Errorf("tool call %s has no result", call.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// errToolDenied is returned by RunTool when the user declines the call.
+var errToolDenied = errors.New("permission denied")
+
+// RunTool invokes a registered tool directly with JSON arguments, going through
+// the usual permission prompt, without involving the model or the conversation.
+// It is meant for debugging tools in isolation.
+func (this *Agent) RunTool(ctx context.Context, name, arguments string) (string, error) {
+	tool, ok := this.tools[name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	params := map[string]interface{}{}
+	if arguments = strings.TrimSpace(arguments); arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), &params); err != nil {
+			return "", fmt.Errorf("arguments must be a JSON object: %v", err)
+		}
+	}
+	if err := tools.ValidateParams(tool.Parameters(), params); err != nil {
+		return "", err
+	}
+	if requiresPermission(tool, params) {
+		allowed, edited := this.askPermission(tool, params)
+		if !allowed {
+			return "", errToolDenied
+		}
+		params = edited
+	}
+	result, err := tool.Execute(ctx, params)
+	return result.Content, err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+func TestRunTool(t *testing.T) {
+	agent := newTestAgent(t, &tools.ReadFileTool{}, &tools.WriteFileTool{})
+	if err := os.WriteFile(filepath.Join(agent.sandbox.Root, "x"), []byte("replayed content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history := len(agent.conversation)
+
+	result, err := agent.RunTool(context.Background(), "read_file", `{"path":"x"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "replayed content") {
+		t.Errorf("got %q", result)
+	}
+
+	useInput(t, "n\n")
+	captureStdout(t, func() {
+		_, err = agent.RunTool(context.Background(), "write_file", `{"path":"y","content":"no"}`)
+	})
+	if !errors.Is(err, errToolDenied) {
+		t.Errorf("a declined write gave %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(agent.sandbox.Root, "y")); !os.IsNotExist(err) {
+		t.Error("the declined write happened")
+	}
+
+	for arguments, want := range map[string]string{
+		`{"path":`: "arguments must be a JSON object",
+		`{}`:       "path",
+	} {
+		if _, err := agent.RunTool(context.Background(), "read_file", arguments); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s gave %v, want an error mentioning %q", arguments, err, want)
+		}
+	}
+	if _, err := agent.RunTool(context.Background(), "nope", ""); err == nil {
+		t.Error("an unknown tool ran")
+	}
+	if len(agent.conversation) != history {
+		t.Errorf("the conversation grew to %d messages", len(agent.conversation))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var sessionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// savedSession is the on-disk form of a conversation. The preamble (project
+// instructions) is not saved; it is rebuilt from the current files on load.
+type savedSession struct {
+	Model    string    `json:"model"`
+	SavedAt  time.Time `json:"saved_at"`
+	Messages []Message `json:"messages"`
+}
+
+// defaultSessionsDir returns the directory sessions are saved to unless -sessions-dir says otherwise.
+func defaultSessionsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cli-ai-agent", "sessions")
+}
+
+// newSessionName names a session after the moment it started.
+func newSessionName() string {
+	return time.Now().Format("20060102-150405")
+}
+
+func sessionFile(dir, name string) (string, error) {
+	if dir == "" {
+		return "", errors.New("sessions are disabled (no -sessions-dir)")
+	}
+	if !sessionName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func writeSessionFile(path string, saved savedSession) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+func readSessionFile(path string) (saved savedSession, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return saved, err
+	}
+	err = json.Unmarshal(content, &saved)
+	return saved, err
+}
+
+// newestSessionFile returns the most recently modified session in dir, or "" if there are none.
+func newestSessionFile(dir string) (newest string, err error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	return newest, nil
+}
+
+// SaveSession writes the conversation under name; with -autosave, later turns
+// are saved there too.
+func (this *Agent) SaveSession(name string) error {
+	path, err := sessionFile(this.sessionsDir, name)
+	if err != nil {
+		return err
+	}
+	this.sessionName = name
+	return this.writeSession(path)
+}
+
+// LoadSession replaces the conversation with the one saved under name.
+func (this *Agent) LoadSession(name string) (messages int, err error) {
+	path, err := sessionFile(this.sessionsDir, name)
+	if err != nil {
+		return 0, err
+	}
+	return this.loadSessionFile(path)
+}
+
+// Resume loads the most recently modified session. It returns the session's
+// name, or "" if there was nothing to resume.
+func (this *Agent) Resume() (name string, messages int, err error) {
+	if this.sessionsDir == "" {
+		return "", 0, errors.New("sessions are disabled (no -sessions-dir)")
+	}
+	path, err := newestSessionFile(this.sessionsDir)
+	if err != nil || path == "" {
+		return "", 0, err
+	}
+	messages, err = this.loadSessionFile(path)
+	return this.sessionName, messages, err
+}
+
+func (this *Agent) loadSessionFile(path string) (int, error) {
+	saved, err := readSessionFile(path)
+	if err != nil {
+		return 0, err
+	}
+	this.Reset()
+	this.conversation = append(this.conversation, saved.Messages...)
+	this.sessionName = strings.TrimSuffix(filepath.Base(path), ".json")
+	return len(saved.Messages), nil
+}
+
+// autosave writes the conversation to the current session file, if -autosave
+// is set and there is anything beyond the preamble to save.
+func (this *Agent) autosave() {
+	if !this.autosaving || this.sessionsDir == "" || len(this.conversation) == this.preambleLen {
+		return
+	}
+	path, err := sessionFile(this.sessionsDir, this.sessionName)
+	if err == nil {
+		err = this.writeSession(path)
+	}
+	if err != nil {
+		log.Printf("Unable to save session: %v", err)
+	}
+}
+
+func (this *Agent) writeSession(path string) error {
+	return writeSessionFile(path, savedSession{
+		Model:    this.model,
+		SavedAt:  time.Now(),
+		Messages: this.conversation[this.preambleLen:],
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeLoadsNewestSession(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	sessions := []struct {
+		name     string
+		messages []Message
+		age      time.Duration
+	}{
+		{name: "old", messages: []Message{{Role: "user", Content: "first"}}, age: 3 * time.Hour},
+		{name: "newest", messages: []Message{{Role: "user", Content: "latest"}, {Role: "assistant", Content: "hi"}}, age: time.Minute},
+		{name: "older", messages: []Message{{Role: "user", Content: "second"}}, age: 2 * time.Hour},
+	}
+	for _, session := range sessions {
+		path := filepath.Join(dir, session.name+".json")
+		if err := writeSessionFile(path, savedSession{Model: "m", Messages: session.messages}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-session.age), now.Add(-session.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	agent := newTestAgent(t)
+	agent.sessionsDir = dir
+
+	name, messages, err := agent.Resume()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "newest" || messages != 2 {
+		t.Errorf("resumed %q with %d messages, want newest with 2", name, messages)
+	}
+	if len(agent.conversation) != 2 || agent.conversation[0].Content != "latest" {
+		t.Errorf("unexpected conversation %+v", agent.conversation)
+	}
+}
+
+func TestResumeWithoutSessions(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.sessionsDir = filepath.Join(t.TempDir(), "missing")
+	if name, _, err := agent.Resume(); name != "" || err != nil {
+		t.Errorf("got %q, %v; want nothing to resume", name, err)
+	}
+}
+
+func TestAutosaveIsOptIn(t *testing.T) {
+	for _, autosaving := range []bool{false, true} {
+		agent := newTestAgent(t)
+		agent.sessionsDir = filepath.Join(t.TempDir(), "sessions")
+		agent.autosaving = autosaving
+		agent.backend = &scriptedBackend{responses: [][]ChatChunk{reply("Hello.")}}
+		agent.Reset()
+		captureStdout(t, func() {
+			if err := agent.ProcessMessage("hi"); err != nil {
+				t.Error(err)
+			}
+		})
+		saved, _ := filepath.Glob(filepath.Join(agent.sessionsDir, "*.json"))
+		if autosaving && len(saved) != 1 {
+			t.Errorf("with -autosave, %d sessions were saved", len(saved))
+		}
+		if !autosaving && len(saved) != 0 {
+			t.Errorf("without -autosave, the conversation was saved to %v", saved)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const searchSnippetContext = 40
+
+// conversationMatch is a message in the conversation that matched a search.
+type conversationMatch struct {
+	Index   int // position in the conversation
+	Turn    int // number of user messages up to and including this one
+	Role    string
+	Snippet string
+}
+
+// searchConversation finds messages whose content matches query. A query
+// wrapped in slashes (/pattern/) is a regular expression; anything else is a
+// case-insensitive substring.
+func searchConversation(messages []Message, query string) (matches []conversationMatch, err error) {
+	var pattern *regexp.Regexp
+	if len(query) > 2 && strings.HasPrefix(query, "/") && strings.HasSuffix(query, "/") {
+		pattern, err = regexp.Compile(query[1 : len(query)-1])
+	} else {
+		pattern, err = regexp.Compile("(?i)" + regexp.QuoteMeta(query))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %v", err)
+	}
+	turn := 0
+	for i, message := range messages {
+		if message.Role == "user" {
+			turn++
+		}
+		location := pattern.FindStringIndex(message.Content)
+		if location == nil {
+			continue
+		}
+		matches = append(matches, conversationMatch{
+			Index:   i,
+			Turn:    turn,
+			Role:    message.Role,
+			Snippet: snippet(message.Content, location[0], location[1]),
+		})
+	}
+	return matches, nil
+}
+
+func snippet(content string, start, end int) string {
+	from := max(0, start-searchSnippetContext)
+	to := min(len(content), end+searchSnippetContext)
+	for from > 0 && !isRuneStart(content[from]) {
+		from--
+	}
+	for to < len(content) && !isRuneStart(content[to]) {
+		to++
+	}
+	text := content[from:start] + "»" + content[start:end] + "«" + content[end:to]
+	text = strings.Join(strings.Fields(text), " ")
+	if from > 0 {
+		text = "…" + text
+	}
+	if to < len(content) {
+		text += "…"
+	}
+	return text
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// Search prints the messages in the conversation that match query.
+func (this *Agent) Search(query string) {
+	matches, err := searchConversation(this.conversation, query)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(matches) == 0 {
+		fmt.Println("No matching messages.")
+		return
+	}
+	for _, match := range matches {
+		fmt.Printf("[#%d turn %d %s] %s\n", match.Index, match.Turn, match.Role, match.Snippet)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearchConversation(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are a coding assistant."},
+		{Role: "user", Content: "How does the parser handle comments?"},
+		{Role: "assistant", Content: "The Parser skips comments in its lexer."},
+		{Role: "user", Content: "And strings?"},
+		{Role: "tool", Content: "func parseString(input string) (string, error)"},
+		{Role: "assistant", Content: "Strings are handled by parseString."},
+	}
+	cases := []struct {
+		query   string
+		indices []int
+		turns   []int
+	}{
+		{query: "parser", indices: []int{1, 2}, turns: []int{1, 1}},
+		{query: "PARSESTRING", indices: []int{4, 5}, turns: []int{2, 2}},
+		{query: `/\bParser\b/`, indices: []int{2}, turns: []int{1}},
+		{query: "/strings?\\?$/", indices: []int{3}, turns: []int{2}},
+		{query: "tokenizer", indices: nil, turns: nil},
+	}
+	for _, test := range cases {
+		t.Run(test.query, func(t *testing.T) {
+			matches, err := searchConversation(messages, test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var indices, turns []int
+			for _, match := range matches {
+				indices = append(indices, match.Index)
+				turns = append(turns, match.Turn)
+				if match.Role != messages[match.Index].Role || !strings.Contains(match.Snippet, "»") {
+					t.Errorf("unexpected match %+v", match)
+				}
+			}
+			if !slices.Equal(indices, test.indices) || !slices.Equal(turns, test.turns) {
+				t.Errorf("matched %v in turns %v, want %v in turns %v", indices, turns, test.indices, test.turns)
+			}
+		})
+	}
+	if _, err := searchConversation(messages, "/(/"); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+}
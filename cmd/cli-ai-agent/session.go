@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+)
+
+// session holds the state shared with ContextAware tools.
+type session struct {
+	readSet    map[string]bool
+	lastWrites map[string]string
+	edits      []fileEdit // the undo journal, oldest first
+}
+
+// fileEdit is what a file held before a tool edited it.
+type fileEdit struct {
+	path    string
+	before  []byte
+	existed bool // false when the tool created the file
+}
+
+// maxUndoEdits bounds the undo journal (and the file contents it keeps).
+const maxUndoEdits = 50
+
+func newSession() *session {
+	return &session{
+		readSet:    make(map[string]bool),
+		lastWrites: make(map[string]string),
+	}
+}
+
+func (this *session) WasRead(path string) bool { return this.readSet[sessionKey(path)] }
+func (this *session) MarkRead(path string)     { this.readSet[sessionKey(path)] = true }
+
+func (this *session) RecordWrite(path, content string) { this.lastWrites[sessionKey(path)] = content }
+func (this *session) LastWrite(path string) (string, bool) {
+	content, ok := this.lastWrites[sessionKey(path)]
+	return content, ok
+}
+
+func (this *session) RecordEdit(path string, before []byte, existed bool) {
+	this.edits = append(this.edits, fileEdit{path: sessionKey(path), before: before, existed: existed})
+	if len(this.edits) > maxUndoEdits {
+		this.edits = slices.Delete(this.edits, 0, 1)
+	}
+}
+
+// popEdit removes and returns the most recent edit in the journal.
+func (this *session) popEdit() (fileEdit, bool) {
+	if len(this.edits) == 0 {
+		return fileEdit{}, false
+	}
+	edit := this.edits[len(this.edits)-1]
+	this.edits = this.edits[:len(this.edits)-1]
+	return edit, true
+}
+
+func (this *session) Reset() {
+	clear(this.readSet)
+	clear(this.lastWrites)
+	this.edits = nil
+}
+
+func sessionKey(path string) string {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return absolute
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+// readSetProbe is a context-aware tool reporting whether its path was read.
+type readSetProbe struct {
+	state tools.SessionState
+}
+
+func (this *readSetProbe) Name() string        { return "probe" }
+func (this *readSetProbe) Description() string { return "Reports whether path was read." }
+func (this *readSetProbe) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (this *readSetProbe) RequiresPermission() bool            { return false }
+func (this *readSetProbe) SetSession(state tools.SessionState) { this.state = state }
+func (this *readSetProbe) Execute(ctx context.Context, params map[string]interface{}) (tools.ToolResult, error) {
+	path, err := tools.GetString(params, "path")
+	if err != nil {
+		return tools.ToolResult{}, err
+	}
+	if this.state.WasRead(path) {
+		return tools.ToolResult{Content: "read"}, nil
+	}
+	return tools.ToolResult{Content: "unread"}, nil
+}
+
+func TestContextAwareToolSeesReadSet(t *testing.T) {
+	probe := &readSetProbe{}
+	agent := newTestAgent(t, probe, &tools.ReadFileTool{}, &tools.ModifyFileTool{})
+	path := filepath.Join(agent.sandbox.Root, "notes.txt")
+	if err := os.WriteFile(path, []byte("old text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	call := func(name string, params map[string]interface{}) (tools.ToolResult, error) {
+		return agent.tools[name].Execute(ctx, params)
+	}
+
+	if result, _ := call("probe", map[string]interface{}{"path": path}); result.Content != "unread" {
+		t.Fatalf("probe says %q before reading", result.Content)
+	}
+	modify := map[string]interface{}{"path": "notes.txt", "search": "old", "replace": "new"}
+	if _, err := call("modify_file", modify); err == nil {
+		t.Fatal("modify_file edited a file that wasn't read")
+	}
+	if _, err := call("read_file", map[string]interface{}{"path": "notes.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := call("probe", map[string]interface{}{"path": path}); result.Content != "read" {
+		t.Errorf("probe says %q after reading", result.Content)
+	}
+	if _, err := call("modify_file", modify); err != nil {
+		t.Errorf("modify_file refused a file that was read: %v", err)
+	}
+
+	agent.session.Reset()
+	if result, _ := call("probe", map[string]interface{}{"path": path}); result.Content != "unread" {
+		t.Errorf("probe says %q after the session was reset", result.Content)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SlashCommands maps a command name to a prompt template. In a template,
+// {{args}} is replaced with everything typed after the command name.
+type SlashCommands map[string]string
+
+// LoadSlashCommands reads templates from a JSON object like
+// {"review": "Review {{args}} for bugs and style issues."}.
+func LoadSlashCommands(path string) (SlashCommands, error) {
+	commands := make(SlashCommands)
+	if path == "" {
+		return commands, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, &commands); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return commands, nil
+}
+
+// Expand turns "/name args" into the templated prompt. Unknown commands are an
+// error rather than being sent to the model literally.
+func (this SlashCommands) Expand(input string) (string, error) {
+	name, args, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	template, ok := this[name]
+	if !ok {
+		return "", fmt.Errorf("unknown command /%s (available: %s)", name, this.names())
+	}
+	args = strings.TrimSpace(args)
+	if !strings.Contains(template, "{{args}}") && args != "" {
+		return template + "\n\n" + args, nil
+	}
+	return strings.ReplaceAll(template, "{{args}}", args), nil
+}
+
+func (this SlashCommands) names() string {
+	if len(this) == 0 {
+		return "none configured; see -slash-commands"
+	}
+	var names []string
+	for name := range this {
+		names = append(names, "/"+name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlashCommandExpands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.json")
+	content := `{"review": "Review {{args}} for bugs and style issues.", "explain": "Explain this code."}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commands, err := LoadSlashCommands(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"/review foo.go":     "Review foo.go for bugs and style issues.",
+		"/explain":           "Explain this code.",
+		"/explain   main.go": "Explain this code.\n\nmain.go",
+	}
+	for input, want := range cases {
+		got, err := commands.Expand(input)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+		} else if got != want {
+			t.Errorf("%s expanded to %q, want %q", input, got, want)
+		}
+	}
+	if _, err := commands.Expand("/deploy prod"); err == nil || !strings.Contains(err.Error(), "/explain, /review") {
+		t.Errorf("an unknown command gave %v", err)
+	}
+}
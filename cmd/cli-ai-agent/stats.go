@@ -0,0 +1,66 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Usage reports what generating a response took, as far as the backend says.
+// Zero fields are unknown.
+type Usage struct {
+	Requests       int           `json:"requests,omitempty"`
+	PromptTokens   int           `json:"prompt_tokens,omitempty"`
+	OutputTokens   int           `json:"output_tokens,omitempty"`
+	Duration       time.Duration `json:"duration,omitempty"`        // the whole request, including loading the model
+	OutputDuration time.Duration `json:"output_duration,omitempty"` // generating the output tokens
+}
+
+func (this *Usage) add(other Usage) {
+	this.Requests += max(other.Requests, 1)
+	this.PromptTokens += other.PromptTokens
+	this.OutputTokens += other.OutputTokens
+	this.Duration += other.Duration
+	this.OutputDuration += other.OutputDuration
+}
+
+// String summarizes the usage in one line, e.g. "120 tokens in 3.4s
+// (35 tok/s), 890 prompt tokens.", leaving out what is unknown.
+func (this Usage) String() string {
+	var parts []string
+	if this.OutputTokens > 0 {
+		part := fmt.Sprintf("%d tokens", this.OutputTokens)
+		if this.Duration > 0 {
+			part += fmt.Sprintf(" in %.1fs", this.Duration.Seconds())
+		}
+		if generating := cmp.Or(this.OutputDuration, this.Duration); generating > 0 {
+			part += fmt.Sprintf(" (%.0f tok/s)", float64(this.OutputTokens)/generating.Seconds())
+		}
+		parts = append(parts, part)
+	} else if this.Duration > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fs", this.Duration.Seconds()))
+	}
+	if this.PromptTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d prompt tokens", this.PromptTokens))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	summary := strings.Join(parts, ", ")
+	if this.Requests > 1 {
+		summary += fmt.Sprintf(" over %d requests", this.Requests)
+	}
+	return summary + "."
+}
+
+// printStats shows the usage of the turn that just ended, when -stats is set
+// and the backend reported any.
+func (this *Agent) printStats() {
+	if !this.stats {
+		return
+	}
+	if summary := this.turnUsage.String(); summary != "" {
+		fmt.Println("\n📊 " + summary)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	summaryInstructions = "You compact conversation histories for an AI coding assistant. " +
+		"Summarize the transcript you are given into a concise digest that preserves the user's goals and instructions, " +
+		"decisions made, files and tools involved (including what tool calls did and what they returned), and any unfinished work. " +
+		"Reply with the summary only."
+	maxTranscriptToolResult = 2000
+)
+
+// SummarizeOldest replaces the count oldest messages after the preamble with a
+// single system message summarizing them, written by SummaryModel (or the
+// agent's own model) in a separate, non-streaming request.
+func (this *Agent) SummarizeOldest(ctx context.Context, count int) error {
+	count = min(count, len(this.conversation)-this.preambleLen)
+	if count <= 0 {
+		return nil
+	}
+	oldest := this.conversation[this.preambleLen : this.preambleLen+count]
+	summary, err := this.summarize(ctx, oldest)
+	if err != nil {
+		return err
+	}
+	this.replaceOldest(count, Message{Role: "system", Content: "Summary of earlier conversation: " + summary})
+	return nil
+}
+
+// replaceOldest swaps the count oldest messages after the preamble for replacement (which may be empty).
+func (this *Agent) replaceOldest(count int, replacement ...Message) {
+	rest := this.conversation[this.preambleLen+count:]
+	this.conversation = append(append(this.conversation[:this.preambleLen:this.preambleLen], replacement...), rest...)
+}
+
+func (this *Agent) summarize(ctx context.Context, messages []Message) (string, error) {
+	stream, err := this.backend.Chat(ctx, ChatRequest{
+		Model: cmp.Or(this.SummaryModel, this.model),
+		Messages: []Message{
+			{Role: "system", Content: summaryInstructions},
+			{Role: "user", Content: transcript(messages)},
+		},
+		Stream:  false,
+		Think:   false,
+		Options: this.options,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summary request failed: %v", err)
+	}
+	reply, err := collect(stream)
+	if err != nil {
+		return "", fmt.Errorf("summary response: %v", err)
+	}
+	summary := strings.TrimSpace(reply.Content)
+	if summary == "" {
+		return "", errors.New("the summary model returned nothing")
+	}
+	return summary, nil
+}
+
+// transcript renders messages as plain text for the summarizer, describing
+// tool calls and their results rather than dropping them.
+func transcript(messages []Message) string {
+	var text strings.Builder
+	for _, message := range messages {
+		switch message.Role {
+		case "tool":
+			result := message.Content
+			if len(result) > maxTranscriptToolResult {
+				result = result[:maxTranscriptToolResult] + "\n[...]"
+			}
+			fmt.Fprintf(&text, "TOOL RESULT:\n%s\n\n", result)
+			continue
+		default:
+			if content := strings.TrimSpace(message.Content); content != "" {
+				fmt.Fprintf(&text, "%s:\n%s\n\n", strings.ToUpper(message.Role), content)
+			}
+		}
+		for _, call := range message.ToolCalls {
+			arguments, _ := json.Marshal(call.Function.Arguments)
+			fmt.Fprintf(&text, "%s CALLED TOOL %s WITH %s\n\n", strings.ToUpper(message.Role), call.Function.Name, arguments)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// SwitchModel makes later turns use model, keeping the conversation. When the
+// backend can list its models and model isn't among them, the switch still
+// happens (the list may be stale) but the returned warning says so.
+func (this *Agent) SwitchModel(model string) (warning string) {
+	this.model = model
+	log.SetPrefix(fmt.Sprintf("[%s] ", model))
+	lister, ok := this.backend.(ModelLister)
+	if !ok {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	available, err := lister.Models(ctx)
+	if err != nil {
+		return fmt.Sprintf("Unable to check whether %s is available: %v", model, err)
+	}
+	if slices.Contains(available, model) || slices.Contains(available, model+":latest") {
+		return ""
+	}
+	return fmt.Sprintf("%s has not been pulled (try 'ollama pull %s'); available: %v", model, model, available)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mdw-tools/cli-ai-agent/pretty"
+)
+
+// Roles passed to a TokenHandler.
+const (
+	tokenThinking = pretty.RoleThinking
+	tokenContent  = pretty.RoleAssistant
+)
+
+// TokenHandler receives streamed response text as it arrives. role is
+// "thinking" for reasoning and "assistant" for the reply itself.
+type TokenHandler func(role, text string)
+
+// terminalTokens returns a TokenHandler that prints one response to stdout,
+// introducing the thinking and the reply with a header each and coloring
+// them by role. With markdown, reply text (which then arrives whole) is
+// rendered with pretty.RenderMarkdown.
+func terminalTokens(markdown bool) TokenHandler {
+	var thinkingDisplayed, contentDisplayed bool
+	return func(role, text string) {
+		switch role {
+		case tokenThinking:
+			if !thinkingDisplayed {
+				fmt.Print("\n💭 Thinking: ")
+				thinkingDisplayed = true
+			}
+		case tokenContent:
+			if !contentDisplayed {
+				if thinkingDisplayed {
+					fmt.Println() // New line after thinking
+				}
+				fmt.Print("\n🤖 Assistant: ")
+				contentDisplayed = true
+			}
+		}
+		if markdown && role == tokenContent {
+			text = pretty.RenderMarkdown(text)
+		}
+		fmt.Print(pretty.Colorize(role, text))
+	}
+}
+
+// tokenHandler returns the handler for one response: the terminal printer
+// (unless TerminalOutput is off) followed by OnToken, if set.
+func (this *Agent) tokenHandler() TokenHandler {
+	var terminal TokenHandler
+	if this.TerminalOutput {
+		terminal = terminalTokens(this.markdown)
+	}
+	return func(role, text string) {
+		if text == "" {
+			return
+		}
+		if terminal != nil {
+			terminal(role, text)
+		}
+		if this.OnToken != nil {
+			this.OnToken(role, text)
+		}
+	}
+}
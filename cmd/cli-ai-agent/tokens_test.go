@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOnTokenReceivesStreamInOrder(t *testing.T) {
+	stream := []ChatChunk{
+		{Role: "assistant", Thinking: "Let me "},
+		{Thinking: "think."},
+		{Content: "The answer"},
+		{Content: " is 42."},
+	}
+	cases := []struct {
+		name         string
+		hideThinking bool
+		want         []string
+	}{
+		{
+			name: "thinking then content",
+			want: []string{"thinking: Let me ", "thinking: think.", "assistant: The answer", "assistant:  is 42."},
+		},
+		{
+			name:         "hidden thinking isn't passed on",
+			hideThinking: true,
+			want:         []string{"assistant: The answer", "assistant:  is 42."},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			agent := newTestAgent(t)
+			agent.hideThinking = test.hideThinking
+			agent.backend = &scriptedBackend{responses: [][]ChatChunk{stream}}
+			var got []string
+			agent.OnToken = func(role, text string) { got = append(got, role+": "+text) }
+
+			output := captureStdout(t, func() {
+				if err := agent.ProcessMessage("what is the answer?"); err != nil {
+					t.Error(err)
+				}
+			})
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if strings.Contains(output, "The answer") {
+				t.Errorf("the reply was printed with TerminalOutput off:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// defaultCoreTools are always offered to the model when -max-tools-in-prompt
+// limits the tool definitions sent with a request.
+const defaultCoreTools = "read_file,write_file,modify_file,run_shell_command"
+
+// selectTools returns the tools to offer for message, sorted by name. With a
+// positive limit and more tools than that, only the core tools plus the tools
+// whose names or descriptions share the most keywords with the message are
+// chosen; the core tools are kept even if they alone exceed the limit.
+func selectTools(registered map[string]Tool, message string, limit int, core []string) []Tool {
+	var selected []Tool
+	if limit <= 0 || len(registered) <= limit {
+		for _, tool := range registered {
+			selected = append(selected, tool)
+		}
+		return sortTools(selected)
+	}
+
+	type candidate struct {
+		tool  Tool
+		score int
+	}
+	var candidates []candidate
+	words := keywords(message)
+	for name, tool := range registered {
+		if slices.Contains(core, name) {
+			selected = append(selected, tool)
+			continue
+		}
+		if score := relevance(words, tool); score > 0 {
+			candidates = append(candidates, candidate{tool, score})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(b.score-a.score, strings.Compare(a.tool.Name(), b.tool.Name()))
+	})
+	for _, candidate := range candidates {
+		if len(selected) >= limit {
+			break
+		}
+		selected = append(selected, candidate.tool)
+	}
+	return sortTools(selected)
+}
+
+func sortTools(tools []Tool) []Tool {
+	slices.SortFunc(tools, func(a, b Tool) int { return strings.Compare(a.Name(), b.Name()) })
+	return tools
+}
+
+// relevance counts the message keywords that appear among the tool's name and description words.
+func relevance(messageWords map[string]bool, tool Tool) (score int) {
+	toolWords := keywords(tool.Name() + " " + tool.Description())
+	for word := range messageWords {
+		if toolWords[word] {
+			score++
+		}
+	}
+	return score
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "are": true, "was": true, "you": true, "your": true, "can": true, "please": true,
+	"what": true, "how": true, "use": true, "all": true, "any": true, "not": true, "but": true,
+	"optional": true, "default": true,
+}
+
+// keywords lowercases text and splits it into words of three or more letters
+// (underscored names count as separate words), minus common stop words. A
+// trailing 's' is dropped so "files" matches "file".
+func keywords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) > 3 {
+			word = strings.TrimSuffix(word, "s")
+		}
+		if len(word) >= 3 && !stopWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mdw-tools/cli-ai-agent/tools"
+)
+
+func TestSelectTools(t *testing.T) {
+	registered := make(map[string]Tool)
+	for _, tool := range []Tool{
+		&tools.ReadFileTool{},
+		&tools.WriteFileTool{},
+		&tools.ModifyFileTool{},
+		&tools.RunCommandTool{},
+		&tools.GitTool{},
+		&tools.GrepTool{},
+		&tools.FetchURLTool{},
+		&tools.ListeningPortsTool{},
+		&tools.GoDocTool{},
+		&tools.LintConfigTool{},
+	} {
+		registered[tool.Name()] = tool
+	}
+	core := strings.Split(defaultCoreTools, ",")
+	cases := []struct {
+		name    string
+		message string
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "no limit sends every tool",
+			message: "hello there",
+			want:    []string{"fetch_url", "git", "go_doc", "grep", "lint_config", "listening_ports", "modify_file", "read_file", "run_shell_command", "write_file"},
+		},
+		{
+			name:    "unrelated message gets only the core tools",
+			message: "Tell me a joke about penguins",
+			limit:   6,
+			want:    []string{"modify_file", "read_file", "run_shell_command", "write_file"},
+		},
+		{
+			name:    "relevant tools fill the limit",
+			message: "Which ports are listening? Also fetch the URL.",
+			limit:   6,
+			want:    []string{"fetch_url", "listening_ports", "modify_file", "read_file", "run_shell_command", "write_file"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, tool := range selectTools(registered, test.message, test.limit, core) {
+				got = append(got, tool.Name())
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
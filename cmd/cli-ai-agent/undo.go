@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Undo reverts the most recent file edit made by a tool (write_file,
+// modify_file, apply_patch, ...): the file's earlier content is restored, or
+// the file is removed if the tool created it. The model is told about the
+// reversal so it doesn't rely on the edit. It returns what was reverted.
+func (this *Agent) Undo() (string, error) {
+	edit, ok := this.session.popEdit()
+	if !ok {
+		return "", errors.New("there are no file edits to undo")
+	}
+	var description string
+	if edit.existed {
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(edit.path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(edit.path, edit.before, mode); err != nil {
+			return "", err
+		}
+		this.session.RecordWrite(edit.path, string(edit.before))
+		description = fmt.Sprintf("restored %s to its earlier content (%d bytes)", edit.path, len(edit.before))
+	} else {
+		if err := os.Remove(edit.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		delete(this.session.lastWrites, sessionKey(edit.path))
+		description = fmt.Sprintf("removed %s, which a tool had created", edit.path)
+	}
+	this.conversation = append(this.conversation, Message{Role: "system", Content: "The user undid a file edit: " + description + "."})
+	return description, nil
+}
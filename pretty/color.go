@@ -0,0 +1,40 @@
+package pretty
+
+import "os"
+
+// Roles understood by Colorize.
+const (
+	RoleThinking  = "thinking"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+	RoleError     = "error"
+)
+
+// Color enables Colorize; see ColorSupported.
+var Color bool
+
+var roleColors = map[string]string{
+	RoleThinking: "\033[90m", // dim gray
+	RoleTool:     "\033[36m", // cyan
+	RoleError:    "\033[31m", // red
+}
+
+// Colorize wraps text in the ANSI color for role when Color is on. Assistant
+// content (and any unknown role) keeps the terminal's default color.
+func Colorize(role, text string) string {
+	code, ok := roleColors[role]
+	if !Color || !ok || text == "" {
+		return text
+	}
+	return code + text + "\033[0m"
+}
+
+// ColorSupported reports whether file is a terminal and NO_COLOR
+// (https://no-color.org) is unset.
+func ColorSupported(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
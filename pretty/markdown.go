@@ -0,0 +1,164 @@
+package pretty
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	bold    = "\033[1m"
+	italic  = "\033[3m"
+	dim     = "\033[90m"
+	cyan    = "\033[36m"
+	green   = "\033[32m"
+	yellow  = "\033[33m"
+	magenta = "\033[35m"
+	reset   = "\033[0m"
+)
+
+// style wraps text in an ANSI code when Color is on.
+func style(code, text string) string {
+	if !Color || text == "" {
+		return text
+	}
+	return code + text + reset
+}
+
+var (
+	markdownHeader   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownNumbered = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	markdownInline   = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*|\\b_[^_\\s][^_]*_\\b")
+)
+
+// RenderMarkdown formats a complete markdown document for the terminal:
+// headers are bold, list items are indented bullets, quotes are dimmed,
+// inline code, bold and italics are styled, and fenced code blocks get basic
+// keyword highlighting for the language named after the fence. Without Color
+// only the layout changes are applied.
+func RenderMarkdown(text string) string {
+	var result []string
+	inCode, language := false, ""
+	for _, line := range strings.Split(text, "\n") {
+		if fence, ok := strings.CutPrefix(strings.TrimSpace(line), "```"); ok {
+			inCode = !inCode
+			language = strings.ToLower(strings.TrimSpace(fence))
+			result = append(result, style(dim, line))
+			continue
+		}
+		if inCode {
+			result = append(result, "  "+highlight(line, language))
+			continue
+		}
+		if match := markdownHeader.FindStringSubmatch(line); match != nil {
+			result = append(result, style(bold, renderInline(match[2])))
+		} else if match := markdownBullet.FindStringSubmatch(line); match != nil {
+			result = append(result, "  "+match[1]+"• "+renderInline(match[2]))
+		} else if match := markdownNumbered.FindStringSubmatch(line); match != nil {
+			result = append(result, "  "+match[1]+match[2]+" "+renderInline(match[3]))
+		} else if quote, ok := strings.CutPrefix(line, ">"); ok {
+			result = append(result, style(dim, "│ "+strings.TrimSpace(quote)))
+		} else {
+			result = append(result, renderInline(line))
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+func renderInline(line string) string {
+	return markdownInline.ReplaceAllStringFunc(line, func(span string) string {
+		switch {
+		case strings.HasPrefix(span, "`"):
+			return style(cyan, strings.Trim(span, "`"))
+		case strings.HasPrefix(span, "**"), strings.HasPrefix(span, "__"):
+			return style(bold, span[2:len(span)-2])
+		default:
+			return style(italic, span[1:len(span)-1])
+		}
+	})
+}
+
+var keywords = map[string][]string{
+	"go": {"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+		"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch",
+		"type", "var", "nil", "true", "false"},
+	"python": {"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else",
+		"except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "None", "nonlocal", "not",
+		"or", "pass", "raise", "return", "True", "False", "try", "while", "with", "yield"},
+	"javascript": {"async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete",
+		"do", "else", "export", "extends", "false", "finally", "for", "function", "if", "import", "in", "instanceof",
+		"let", "new", "null", "return", "switch", "this", "throw", "true", "try", "typeof", "undefined", "var",
+		"while", "yield", "interface", "type"},
+	"shell": {"case", "do", "done", "elif", "else", "esac", "export", "fi", "for", "function", "if", "in", "local",
+		"return", "then", "until", "while"},
+	"rust": {"as", "async", "await", "break", "const", "continue", "crate", "else", "enum", "false", "fn", "for",
+		"if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub", "ref", "return", "self", "Self",
+		"static", "struct", "trait", "true", "type", "unsafe", "use", "where", "while"},
+}
+
+var languageAliases = map[string]string{
+	"golang": "go", "py": "python", "python3": "python", "js": "javascript", "ts": "javascript",
+	"typescript": "javascript", "jsx": "javascript", "tsx": "javascript", "sh": "shell", "bash": "shell",
+	"zsh": "shell", "console": "shell", "rs": "rust",
+}
+
+// highlight colors keywords, strings, numbers and line comments in one line
+// of code. Unknown languages are left as they are.
+func highlight(line, language string) string {
+	if alias, ok := languageAliases[language]; ok {
+		language = alias
+	}
+	words, ok := keywords[language]
+	if !ok || !Color {
+		return line
+	}
+	comment := "//"
+	if language == "python" || language == "shell" {
+		comment = "#"
+	}
+	var result strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case strings.HasPrefix(string(runes[i:]), comment):
+			result.WriteString(style(dim, string(runes[i:])))
+			return result.String()
+		case r == '"' || r == '\'' || r == '`':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			result.WriteString(style(green, string(runes[i:end])))
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			word := string(runes[i:end])
+			if slices.Contains(words, word) {
+				word = style(magenta, word)
+			}
+			result.WriteString(word)
+			i = end
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.' || unicode.IsLetter(runes[end])) {
+				end++
+			}
+			result.WriteString(style(yellow, string(runes[i:end])))
+			i = end
+		default:
+			result.WriteRune(r)
+			i++
+		}
+	}
+	return result.String()
+}
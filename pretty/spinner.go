@@ -10,43 +10,47 @@ import (
 type Spinner struct {
 	mu      sync.Mutex
 	active  bool
-	done    chan bool
+	done    chan struct{}
+	stopped chan struct{}
 	message string
 }
 
 func NewSpinner(message string) *Spinner {
-	return &Spinner{
-		done:    make(chan bool),
-		message: message,
-	}
+	return &Spinner{message: message}
 }
 
+// Start shows the spinner until Stop is called. Starting an active spinner
+// does nothing, and a stopped spinner may be started again.
 func (this *Spinner) Start() {
 	this.mu.Lock()
+	defer this.mu.Unlock()
 	if this.active {
-		this.mu.Unlock()
 		return
 	}
 	this.active = true
-	this.mu.Unlock()
+	this.done = make(chan struct{})
+	this.stopped = make(chan struct{})
+	go this.spin(this.done, this.stopped)
+}
 
-	go func() {
-		chars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-		i := 0
-		for {
-			select {
-			case <-this.done:
-				fmt.Print("\r\033[K") // Clear the line
-				return
-			default:
-				fmt.Printf("\r%s %s", chars[i], this.message)
-				i = (i + 1) % len(chars)
-				time.Sleep(80 * time.Millisecond)
-			}
+func (this *Spinner) spin(done <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	chars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	ticker := time.NewTicker(80 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i = (i + 1) % len(chars) {
+		fmt.Printf("\r%s %s", chars[i], this.message)
+		select {
+		case <-done:
+			fmt.Print("\r\033[K") // Clear the line
+			return
+		case <-ticker.C:
 		}
-	}()
+	}
 }
 
+// Stop returns once the spinner's line has been cleared, so output printed
+// afterwards isn't overwritten. Stopping an inactive spinner does nothing.
 func (this *Spinner) Stop() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
@@ -54,5 +58,6 @@ func (this *Spinner) Stop() {
 		return
 	}
 	this.active = false
-	this.done <- true
+	close(this.done)
+	<-this.stopped
 }
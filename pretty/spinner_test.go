@@ -0,0 +1,90 @@
+package pretty
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// silenceStdout discards what the spinner prints, returning it once the test
+// is over through the returned function.
+func silenceStdout(t *testing.T) (printed func() string) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- string(content)
+	}()
+	var once sync.Once
+	var content string
+	printed = func() string {
+		once.Do(func() {
+			os.Stdout = original
+			_ = writer.Close()
+			content = <-output
+		})
+		return content
+	}
+	t.Cleanup(func() { printed() })
+	return printed
+}
+
+func TestSpinnerStopWithoutStart(t *testing.T) {
+	printed := silenceStdout(t)
+	NewSpinner("Thinking…").Stop()
+	if output := printed(); output != "" {
+		t.Errorf("printed %q", output)
+	}
+}
+
+func TestSpinnerStopTwice(t *testing.T) {
+	printed := silenceStdout(t)
+	spinner := NewSpinner("Thinking…")
+	spinner.Start()
+	spinner.Stop()
+	spinner.Stop()
+	output := printed()
+	if !strings.HasPrefix(output, "\r⠋ Thinking…") || !strings.HasSuffix(output, "\r\033[K") {
+		t.Errorf("printed %q, want the spinner and then a cleared line", output)
+	}
+	if strings.Count(output, "\r\033[K") != 1 {
+		t.Errorf("the line was cleared more than once: %q", output)
+	}
+}
+
+func TestSpinnerRapidStartStop(t *testing.T) {
+	silenceStdout(t)
+	spinner := NewSpinner("Thinking…")
+	var workers sync.WaitGroup
+	for range 4 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for range 200 {
+				spinner.Start()
+				spinner.Start()
+				spinner.Stop()
+			}
+		}()
+	}
+	finished := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Start and Stop deadlocked")
+	}
+	spinner.Stop()
+}
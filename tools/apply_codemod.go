@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxCodemodFiles    = 500
+	codemodFileTimeout = 30 * time.Second
+)
+
+// ApplyCodemodTool rewrites files with a gofmt -r rule or a Python script,
+// computing every result before writing anything so the change is all-or-nothing.
+type ApplyCodemodTool struct {
+	Sandbox
+	Session
+	Runner CommandRunner
+}
+
+func (this *ApplyCodemodTool) Name() string { return "apply_codemod" }
+func (this *ApplyCodemodTool) Description() string {
+	return "Apply an automated rewrite to every matching file under a path, all-or-nothing, and show the aggregate diff. " +
+		"Provide either rule, a gofmt rewrite rule such as 'oldName -> newName' or 'a[b:len(a)] -> a[b:]' (Go files only), " +
+		"or script, a Python 3 program that is run once per file with the path of a temporary copy as sys.argv[1] and must rewrite that copy in place. " +
+		"If any file fails, nothing is written. With preview=true nothing is written either."
+}
+func (this *ApplyCodemodTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory (or single file) to rewrite",
+			},
+			"rule": map[string]interface{}{
+				"type":        "string",
+				"description": "gofmt rewrite rule 'pattern -> replacement' (use this or script)",
+			},
+			"script": map[string]interface{}{
+				"type":        "string",
+				"description": "Python 3 script that rewrites the file named by sys.argv[1] in place (use this or rule)",
+			},
+			"glob": map[string]interface{}{
+				"type":        "string",
+				"description": "Only rewrite files whose name (or relative path) matches this glob (optional, default '*.go' for rules, all files for scripts)",
+			},
+			"preview": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only show the diff, without writing (optional, default false)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+func (this *ApplyCodemodTool) RequiresPermission() bool { return true }
+
+// DryRunPreview shows the diff a gofmt rule would produce. Scripts are not
+// previewed because running them is itself the thing being approved.
+func (this *ApplyCodemodTool) DryRunPreview(params map[string]interface{}) (string, bool) {
+	if rule, _ := GetOptionalString(params, "rule", ""); rule == "" {
+		return "", false
+	}
+	changes, err := this.plan(context.Background(), params)
+	if err != nil {
+		return "Error: " + err.Error(), true
+	}
+	return changes.diff(), true
+}
+func (this *ApplyCodemodTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	changes, err := this.plan(ctx, params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if len(changes) == 0 {
+		return ToolResult{Content: "The codemod made no changes."}, nil
+	}
+	preview, err := GetBool(params, "preview", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if preview {
+		return ToolResult{Content: fmt.Sprintf("Preview (nothing written): would change %d file(s):\n%s", len(changes), changes.diff())}, nil
+	}
+	if err := changes.apply(); err != nil {
+		return ToolResult{}, err
+	}
+	for _, change := range changes {
+		this.recordWrite(change.path, change.after)
+		this.recordEdit(change.path, []byte(change.before), nil)
+		this.markRead(change.path)
+	}
+	return ToolResult{Content: fmt.Sprintf("Changed %d file(s):\n%s", len(changes), changes.diff())}, nil
+}
+
+type codemodChange struct {
+	path          string
+	mode          fs.FileMode
+	before, after string
+}
+
+type codemodChanges []codemodChange
+
+// plan computes the rewritten content of every matching file without writing any.
+func (this *ApplyCodemodTool) plan(ctx context.Context, params map[string]interface{}) (changes codemodChanges, err error) {
+	root, err := GetString(params, "path")
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return nil, errors.New("path parameter must be a non-empty string")
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := GetOptionalString(params, "rule", "")
+	if err != nil {
+		return nil, err
+	}
+	script, err := GetOptionalString(params, "script", "")
+	if err != nil {
+		return nil, err
+	}
+	if (rule == "") == (script == "") {
+		return nil, errors.New("provide exactly one of rule or script")
+	}
+	if rule != "" && !strings.Contains(rule, "->") {
+		return nil, fmt.Errorf("invalid rule %q: expected 'pattern -> replacement'", rule)
+	}
+	defaultGlob := ""
+	if rule != "" {
+		defaultGlob = "*.go"
+	}
+	glob, err := GetOptionalString(params, "glob", defaultGlob)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && matchesFileGlob(glob, root, path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) > maxCodemodFiles {
+		return nil, fmt.Errorf("%d files match; narrow path or glob (limit %d)", len(paths), maxCodemodFiles)
+	}
+
+	workDir, err := os.MkdirTemp("", "codemod-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+	scriptPath := filepath.Join(workDir, "codemod.py")
+	if script != "" {
+		if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	runner := runnerOrDefault(this.Runner)
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		before, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var after string
+		if rule != "" {
+			after, err = rewriteWithRule(ctx, runner, rule, path)
+		} else {
+			after, err = rewriteWithScript(ctx, runner, scriptPath, workDir, path, before)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v (nothing was written)", path, err)
+		}
+		if after != string(before) {
+			changes = append(changes, codemodChange{path: path, mode: info.Mode().Perm(), before: string(before), after: after})
+		}
+	}
+	return changes, nil
+}
+
+func rewriteWithRule(ctx context.Context, runner CommandRunner, rule, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, codemodFileTimeout)
+	defer cancel()
+	output, err := runner(ctx, Command{Name: "gofmt", Args: []string{"-r", rule, path}})
+	if err != nil {
+		return "", fmt.Errorf("gofmt -r failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func rewriteWithScript(ctx context.Context, runner CommandRunner, scriptPath, workDir, path string, content []byte) (string, error) {
+	copyPath := filepath.Join(workDir, "target"+filepath.Ext(path))
+	if err := os.WriteFile(copyPath, content, 0600); err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, codemodFileTimeout)
+	defer cancel()
+	output, err := runner(ctx, Command{Dir: workDir, Name: "python3", Args: []string{scriptPath, copyPath}})
+	if err != nil {
+		return "", fmt.Errorf("script failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	rewritten, err := os.ReadFile(copyPath)
+	return string(rewritten), err
+}
+
+// apply writes every change, restoring the files already written if one fails.
+func (this codemodChanges) apply() error {
+	for i, change := range this {
+		if err := os.WriteFile(change.path, []byte(change.after), change.mode); err != nil {
+			for _, written := range this[:i] {
+				_ = os.WriteFile(written.path, []byte(written.before), written.mode)
+			}
+			return fmt.Errorf("writing %s: %v (all changes were rolled back)", change.path, err)
+		}
+	}
+	return nil
+}
+
+func (this codemodChanges) diff() string {
+	if len(this) == 0 {
+		return "(no changes)"
+	}
+	var diff strings.Builder
+	for _, change := range this {
+		diff.WriteString(unifiedDiff(change.path, change.path, change.before, change.after))
+	}
+	if diff.Len() > maxStreamEditDiffBytes {
+		return diff.String()[:maxStreamEditDiffBytes] + "\n[diff truncated]\n"
+	}
+	return diff.String()
+}
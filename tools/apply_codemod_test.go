@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyCodemodRenamesAcrossFiles(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt is not installed")
+	}
+	files := map[string]string{
+		"a.go":     "package a\n\nfunc oldName() int { return 1 }\n",
+		"sub/b.go": "package sub\n\nvar x = oldName() + oldName()\n",
+		"sub/c.go": "package sub\n\nvar y = 2\n",
+	}
+	want := map[string]string{
+		"a.go":     "package a\n\nfunc newName() int { return 1 }\n",
+		"sub/b.go": "package sub\n\nvar x = newName() + newName()\n",
+		"sub/c.go": files["sub/c.go"],
+	}
+	cases := []struct {
+		name    string
+		preview bool
+		want    map[string]string
+	}{
+		{name: "preview writes nothing", preview: true, want: files},
+		{name: "apply", want: want},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, files)
+			tool := &ApplyCodemodTool{Sandbox: Sandbox{Root: dir}}
+			params := map[string]interface{}{"path": ".", "rule": "oldName -> newName", "preview": test.preview}
+
+			result, err := tool.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(result.Content, "2 file(s)") || !strings.Contains(result.Content, "+var x = newName() + newName()") {
+				t.Errorf("unexpected result:\n%s", result.Content)
+			}
+			for name, content := range test.want {
+				if got := readTestFile(t, filepath.Join(dir, name)); got != content {
+					t.Errorf("%s = %q, want %q", name, got, content)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyCodemodIsAllOrNothing(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt is not installed")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go": "package a\n\nvar x = oldName\n",
+		"b.go": "package a\n\nfunc broken( {\n",
+	}
+	writeTestFiles(t, dir, files)
+	tool := &ApplyCodemodTool{Sandbox: Sandbox{Root: dir}}
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"path": ".", "rule": "oldName -> newName"})
+	if err == nil || !strings.Contains(err.Error(), "nothing was written") {
+		t.Fatalf("got %v, want a failure that writes nothing", err)
+	}
+	for name, content := range files {
+		if got := readTestFile(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("%s was changed to %q", name, got)
+		}
+	}
+}
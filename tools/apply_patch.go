@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatchTool applies a unified diff to a single file, refusing to write
+// anything unless every hunk's context and removed lines match the file.
+type ApplyPatchTool struct {
+	Sandbox
+	Session
+}
+
+func (this *ApplyPatchTool) Name() string { return "apply_patch" }
+func (this *ApplyPatchTool) Description() string {
+	return "Apply a unified diff (as produced by 'diff -u' or 'git diff') to one file. " +
+		"Every hunk's context (' ') and removed ('-') lines must match the file, though a hunk may sit at a different line than its @@ header says; " +
+		"if any hunk doesn't match, nothing is written and the failing hunk is reported. " +
+		"A diff from /dev/null creates a new file. An existing file must have been read in this session first."
+}
+func (this *ApplyPatchTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to patch (or create).",
+			},
+			"patch": map[string]interface{}{
+				"type":        "string",
+				"description": "The unified diff, with '@@ -start,count +start,count @@' hunk headers (the ---/+++ file headers are optional).",
+			},
+		},
+		"required": []string{"path", "patch"},
+	}
+}
+func (this *ApplyPatchTool) RequiresPermission() bool { return true }
+func (this *ApplyPatchTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	text, err := GetString(params, "patch")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return ToolResult{}, errors.New("patch parameter must be a non-empty unified diff")
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	patch, err := parsePatch(text)
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	var original string
+	raw, readErr := os.ReadFile(path)
+	switch {
+	case patch.creates && readErr == nil:
+		return ToolResult{}, fmt.Errorf("the patch creates %s (it is against /dev/null), but the file already exists", path)
+	case patch.creates:
+	case errors.Is(readErr, os.ErrNotExist):
+		return ToolResult{}, fmt.Errorf("%s does not exist; to create it, write the patch against /dev/null", path)
+	case readErr != nil:
+		return ToolResult{}, readErr
+	case !this.wasRead(path):
+		return ToolResult{}, fmt.Errorf("%s has not been read in this session; read it (e.g. with read_file) before patching it so the patch is based on its current contents", path)
+	default:
+		original = string(raw)
+	}
+
+	content, notes, err := patch.apply(original)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("patch does not apply to %s: %w", path, err)
+	}
+	var createdDir string
+	if patch.creates {
+		if createdDir, err = createParentDirs(path); err != nil {
+			return ToolResult{}, err
+		}
+	}
+	if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+		return ToolResult{}, err
+	}
+	this.recordWrite(path, content)
+	this.recordEdit(path, raw, readErr)
+
+	verb := "Patched"
+	if patch.creates {
+		verb = "Created"
+	}
+	result := fmt.Sprintf("%s %s: applied %d hunk(s), +%d -%d lines.", verb, path, len(patch.hunks), patch.added(), patch.removed())
+	if createdDir != "" {
+		result += "\nCreated directory " + createdDir + "."
+	}
+	for _, note := range notes {
+		result += "\n" + note
+	}
+	return ToolResult{Content: result, Metadata: map[string]interface{}{MetaPath: path, MetaBytesWritten: len(content)}}, nil
+}
+
+type patchHunk struct {
+	header   string
+	oldStart int
+	lines    []diffOp
+}
+
+// before returns the lines the hunk expects to find; after returns what replaces them.
+func (this patchHunk) before() (lines []string) { return this.side('+') }
+func (this patchHunk) after() (lines []string)  { return this.side('-') }
+func (this patchHunk) side(exclude byte) (lines []string) {
+	for _, op := range this.lines {
+		if op.kind != exclude {
+			lines = append(lines, op.line)
+		}
+	}
+	return lines
+}
+
+func (this patchHunk) String() string {
+	var result strings.Builder
+	result.WriteString(this.header)
+	for _, op := range this.lines {
+		result.WriteByte('\n')
+		result.WriteByte(op.kind)
+		result.WriteString(op.line)
+	}
+	return result.String()
+}
+
+type parsedPatch struct {
+	hunks       []patchHunk
+	creates     bool
+	noNewlineAt bool // the new file lacks a trailing newline ("\ No newline at end of file")
+}
+
+func (this parsedPatch) added() (count int)   { return this.count('+') }
+func (this parsedPatch) removed() (count int) { return this.count('-') }
+func (this parsedPatch) count(kind byte) (count int) {
+	for _, hunk := range this.hunks {
+		for _, op := range hunk.lines {
+			if op.kind == kind {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parsePatch reads the hunks of a single-file unified diff. Hunk line counts
+// are not enforced (models often get them wrong); a hunk runs until the next
+// header. A blank line inside a hunk is taken as an empty context line.
+func parsePatch(text string) (patch parsedPatch, err error) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+	var fileHeaders int
+	var current *patchHunk
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "--- ") && (i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")):
+			if fileHeaders++; fileHeaders > 1 {
+				return patch, errors.New("the patch touches more than one file; apply_patch takes a diff for a single file")
+			}
+			patch.creates = strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(line, "--- ")), "/dev/null")
+			current = nil
+		case strings.HasPrefix(line, "+++ ") && current == nil:
+			if strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(line, "+++ ")), "/dev/null") {
+				return patch, errors.New("the patch deletes the file, which apply_patch does not support")
+			}
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return patch, fmt.Errorf("malformed hunk header on line %d: %q", i+1, line)
+			}
+			start, _ := strconv.Atoi(match[1])
+			patch.hunks = append(patch.hunks, patchHunk{header: line, oldStart: start})
+			current = &patch.hunks[len(patch.hunks)-1]
+		case current == nil:
+			// preamble: "diff --git", "index ...", commit messages, etc.
+		case strings.HasPrefix(line, `\`):
+			if n := len(current.lines); n > 0 && current.lines[n-1].kind != '-' {
+				patch.noNewlineAt = true
+			}
+		case line == "":
+			current.lines = append(current.lines, diffOp{kind: ' '})
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			current.lines = append(current.lines, diffOp{kind: line[0], line: line[1:]})
+		default:
+			return patch, fmt.Errorf("unexpected line %d in hunk %q: %q (hunk lines must start with ' ', '-' or '+')", i+1, current.header, line)
+		}
+	}
+	if len(patch.hunks) == 0 {
+		return patch, errors.New("the patch contains no hunks (expected '@@ -start,count +start,count @@' headers)")
+	}
+	for _, hunk := range patch.hunks {
+		if patch.creates && len(hunk.before()) > 0 {
+			return patch, fmt.Errorf("hunk %q has context or removed lines, but the patch creates a new file", hunk.header)
+		}
+	}
+	return patch, nil
+}
+
+// apply returns content with every hunk applied, in order. A hunk whose lines
+// aren't at the position its header names is applied at the nearest position
+// (after the previous hunk) where they do match, and a note says so.
+func (this parsedPatch) apply(content string) (result string, notes []string, err error) {
+	lines := splitLines(content)
+	var output []string
+	next := 0   // first line of the original not yet copied to output
+	offset := 0 // shift observed on earlier hunks, applied to later headers
+	for number, hunk := range this.hunks {
+		old := hunk.before()
+		want := hunk.oldStart - 1 + offset
+		if len(old) == 0 {
+			want = hunk.oldStart + offset // pure insertions name the line they follow
+		}
+		at := findLines(lines, old, next, max(want, next))
+		if at < 0 {
+			return "", nil, fmt.Errorf("hunk %d does not match the file (its context or removed lines differ; re-read the file and regenerate the diff):\n%s", number+1, hunk)
+		}
+		if at != want {
+			notes = append(notes, fmt.Sprintf("hunk %d applied at line %d (offset %+d lines)", number+1, at+1, at-want))
+			offset += at - want
+		}
+		output = append(output, lines[next:at]...)
+		output = append(output, hunk.after()...)
+		next = at + len(old)
+	}
+	output = append(output, lines[next:]...)
+
+	result = strings.Join(output, "\n")
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	if len(output) > 0 && trailingNewline && !this.noNewlineAt {
+		result += "\n"
+	}
+	return result, notes, nil
+}
+
+// findLines returns the index at or after from where lines[i:] begins with
+// want, choosing the match closest to near, or -1.
+func findLines(lines, want []string, from, near int) int {
+	matches := func(at int) bool {
+		if at < from || at+len(want) > len(lines) {
+			return false
+		}
+		for i, line := range want {
+			if lines[at+i] != line {
+				return false
+			}
+		}
+		return true
+	}
+	for distance := 0; near-distance >= from || near+distance <= len(lines); distance++ {
+		if matches(near - distance) {
+			return near - distance
+		}
+		if matches(near + distance) {
+			return near + distance
+		}
+	}
+	return -1
+}
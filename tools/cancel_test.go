@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancelAfter is a context that reports cancellation once Err has been
+// checked checks times, i.e. partway through a walk.
+type cancelAfter struct {
+	context.Context
+	checks int
+}
+
+func (this *cancelAfter) Err() error {
+	if this.checks--; this.checks < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestCancelingLongWalk(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := range 500 {
+		files[fmt.Sprintf("d%02d/f%03d.txt", i%20, i)] = "needle\n"
+	}
+	writeTestFiles(t, dir, files)
+	sandbox := Sandbox{Root: dir}
+	type tool interface {
+		Name() string
+		Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error)
+	}
+	cases := []struct {
+		tool   tool
+		params map[string]interface{}
+	}{
+		{&ListTreeTool{Sandbox: sandbox}, map[string]interface{}{"path": "."}},
+		{&ReadAllFilesInDirectoryTool{Sandbox: sandbox}, map[string]interface{}{"path": "."}},
+		{&GrepTool{Sandbox: sandbox}, map[string]interface{}{"pattern": "needle"}},
+		{&FindFilesTool{Sandbox: sandbox}, map[string]interface{}{"name_pattern": "*.txt"}},
+	}
+	for _, test := range cases {
+		t.Run(test.tool.Name(), func(t *testing.T) {
+			ctx := &cancelAfter{Context: context.Background(), checks: 10}
+			result, err := test.tool.Execute(ctx, test.params)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("got %v and %d bytes of results, want the walk canceled", err, len(result.Content))
+			}
+			if ctx.checks > 0 {
+				t.Errorf("canceled before the walk started")
+			}
+			if strings.Count(result.Content, "needle") >= 500 {
+				t.Error("the walk ran to completion")
+			}
+		})
+	}
+}
+
+func TestCancelingCommand(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+	started := time.Now()
+	_, err := (&RunCommandTool{}).Execute(ctx, map[string]interface{}{"command": "echo started; sleep 30"})
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("took %s to stop the command", elapsed)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "command canceled") || !strings.Contains(err.Error(), "started") {
+		t.Errorf("got %v, want the cancellation with the output so far", err)
+	}
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSkippedShown is how many unreadable entries a summary names.
+const maxSkippedShown = 5
+
+// cappedResults collects result lines up to a limit but keeps counting past it,
+// so a truncated listing can still report the true total ("showing 500 of 1342
+// matches") and the model knows whether what it needs may lie beyond the cap.
+type cappedResults struct {
+	limit   int
+	lines   []string
+	total   int
+	skipped []string
+}
+
+func newCappedResults(limit int) *cappedResults {
+	return &cappedResults{limit: limit}
+}
+
+// Add counts a result, keeping it only while under the limit.
+func (this *cappedResults) Add(line string) {
+	this.total++
+	if this.total <= this.limit {
+		this.lines = append(this.lines, line)
+	}
+}
+
+// Skip records an entry that couldn't be read, so the summary can say the
+// search was incomplete.
+func (this *cappedResults) Skip(path string) {
+	this.skipped = append(this.skipped, path)
+}
+
+func (this *cappedResults) Truncated() bool { return this.total > this.limit }
+
+// String renders the kept lines followed by a summary naming the kind of result (e.g. "matches").
+func (this *cappedResults) String(noun string) string {
+	var result strings.Builder
+	if this.total == 0 {
+		_, _ = fmt.Fprintf(&result, "No %s found.", noun)
+	}
+	for _, line := range this.lines {
+		result.WriteString(line + "\n")
+	}
+	if this.Truncated() {
+		_, _ = fmt.Fprintf(&result, "\n[truncated: showing %d of %d %s; narrow the search to see the rest]\n", len(this.lines), this.total, noun)
+	} else if this.total > 0 {
+		_, _ = fmt.Fprintf(&result, "\n[%d %s]\n", this.total, noun)
+	}
+	if len(this.skipped) > 0 {
+		shown := this.skipped[:min(len(this.skipped), maxSkippedShown)]
+		more := ""
+		if len(this.skipped) > len(shown) {
+			more = fmt.Sprintf(", and %d more", len(this.skipped)-len(shown))
+		}
+		_, _ = fmt.Fprintf(&result, "\n[skipped %d unreadable entries: %s%s]\n", len(this.skipped), strings.Join(shown, ", "), more)
+	}
+	return result.String()
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCappedResultsReportsTotalBeyondLimit(t *testing.T) {
+	results := newCappedResults(5)
+	for i := range 12 {
+		results.Add(fmt.Sprintf("match %d", i))
+	}
+	if !results.Truncated() {
+		t.Error("expected the results to be truncated")
+	}
+	if len(results.lines) != 5 || results.total != 12 {
+		t.Errorf("kept %d of %d, want 5 of 12", len(results.lines), results.total)
+	}
+	output := results.String("matches")
+	if !strings.Contains(output, "showing 5 of 12 matches") {
+		t.Errorf("total missing from:\n%s", output)
+	}
+	if strings.Contains(output, "match 5") {
+		t.Errorf("a result past the limit was kept:\n%s", output)
+	}
+}
+
+func TestCappedResultsUnderLimit(t *testing.T) {
+	results := newCappedResults(5)
+	results.Add("only")
+	if results.Truncated() || results.String("files") != "only\n\n[1 files]\n" {
+		t.Errorf("got %q", results.String("files"))
+	}
+	if empty := newCappedResults(5).String("files"); empty != "No files found." {
+		t.Errorf("got %q", empty)
+	}
+}
+
+func TestCappedResultsMentionsSkippedEntries(t *testing.T) {
+	results := newCappedResults(5)
+	for i := range 7 {
+		results.Skip(fmt.Sprintf("dir%d", i))
+	}
+	want := "No files found.\n[skipped 7 unreadable entries: dir0, dir1, dir2, dir3, dir4, and 2 more]\n"
+	if got := results.String("files"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
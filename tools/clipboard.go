@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const maxClipboardBytes = 64 * 1024
+
+// Clipboard locates the platform clipboard utility. Its fields default to the
+// real platform and may be set to simulate another one.
+type Clipboard struct {
+	Runner   CommandRunner
+	GOOS     string
+	LookPath func(file string) (string, error)
+	Getenv   func(key string) string
+}
+
+type clipboardCommands struct {
+	read, write Command
+}
+
+// candidates lists the utilities to try on each platform, in order of preference.
+func (this *Clipboard) candidates() []clipboardCommands {
+	switch cmp.Or(this.GOOS, runtime.GOOS) {
+	case "darwin":
+		return []clipboardCommands{{read: Command{Name: "pbpaste"}, write: Command{Name: "pbcopy"}}}
+	case "windows":
+		return []clipboardCommands{{
+			read:  Command{Name: "powershell", Args: []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}},
+			write: Command{Name: "clip"},
+		}}
+	}
+	var candidates []clipboardCommands
+	getenv := this.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, clipboardCommands{read: Command{Name: "wl-paste", Args: []string{"--no-newline"}}, write: Command{Name: "wl-copy"}})
+	}
+	return append(candidates,
+		clipboardCommands{read: Command{Name: "xclip", Args: []string{"-selection", "clipboard", "-o"}}, write: Command{Name: "xclip", Args: []string{"-selection", "clipboard"}}},
+		clipboardCommands{read: Command{Name: "xsel", Args: []string{"--clipboard", "--output"}}, write: Command{Name: "xsel", Args: []string{"--clipboard", "--input"}}},
+	)
+}
+
+// commands returns the first clipboard utility that is installed.
+func (this *Clipboard) commands() (clipboardCommands, error) {
+	lookPath := this.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	var tried []string
+	for _, candidate := range this.candidates() {
+		if _, err := lookPath(candidate.read.Name); err == nil {
+			if _, err := lookPath(candidate.write.Name); err == nil {
+				return candidate, nil
+			}
+		}
+		tried = append(tried, candidate.read.Name)
+	}
+	return clipboardCommands{}, fmt.Errorf("no clipboard utility found (tried %s)", strings.Join(tried, ", "))
+}
+
+func (this *Clipboard) run(ctx context.Context, command Command) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	output, err := runnerOrDefault(this.Runner)(ctx, command)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v: %s", command.Name, err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// ReadClipboardTool returns the text on the system clipboard.
+type ReadClipboardTool struct {
+	Clipboard
+}
+
+func (this *ReadClipboardTool) Name() string { return "read_clipboard" }
+func (this *ReadClipboardTool) Description() string {
+	return "Read the text currently on the system clipboard"
+}
+func (this *ReadClipboardTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+func (this *ReadClipboardTool) RequiresPermission() bool { return false }
+func (this *ReadClipboardTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	commands, err := this.commands()
+	if err != nil {
+		return ToolResult{}, err
+	}
+	text, err := this.run(ctx, commands.read)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if text == "" {
+		return ToolResult{Content: "The clipboard is empty."}, nil
+	}
+	if len(text) > maxClipboardBytes {
+		text = text[:maxClipboardBytes] + fmt.Sprintf("\n[truncated: clipboard holds %d bytes]", len(text))
+	}
+	return ToolResult{Content: text}, nil
+}
+
+// WriteClipboardTool places text on the system clipboard.
+type WriteClipboardTool struct {
+	Clipboard
+}
+
+func (this *WriteClipboardTool) Name() string { return "write_clipboard" }
+func (this *WriteClipboardTool) Description() string {
+	return "Copy text to the system clipboard, replacing its contents"
+}
+func (this *WriteClipboardTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "The text to copy",
+			},
+		},
+		"required": []string{"text"},
+	}
+}
+func (this *WriteClipboardTool) RequiresPermission() bool { return true }
+func (this *WriteClipboardTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	text, err := GetString(params, "text")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	commands, err := this.commands()
+	if err != nil {
+		return ToolResult{}, err
+	}
+	command := commands.write
+	command.Stdin = text
+	if _, err := this.run(ctx, command); err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: fmt.Sprintf("Copied %d bytes to the clipboard.", len(text))}, nil
+}
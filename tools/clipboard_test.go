@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeClipboard simulates goos with the given utilities installed, keeping
+// the clipboard's contents in text.
+func fakeClipboard(goos string, env map[string]string, installed []string, text *string, ran *[]Command) Clipboard {
+	return Clipboard{
+		GOOS:   goos,
+		Getenv: func(key string) string { return env[key] },
+		LookPath: func(file string) (string, error) {
+			if slices.Contains(installed, file) {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("not found")
+		},
+		Runner: func(ctx context.Context, command Command) ([]byte, error) {
+			*ran = append(*ran, command)
+			if command.Stdin != "" {
+				*text = command.Stdin
+				return nil, nil
+			}
+			return []byte(*text), nil
+		},
+	}
+}
+
+func TestClipboardPlatforms(t *testing.T) {
+	cases := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		read      Command
+		write     Command
+	}{
+		{
+			name:      "macOS",
+			goos:      "darwin",
+			installed: []string{"pbcopy", "pbpaste"},
+			read:      Command{Name: "pbpaste"},
+			write:     Command{Name: "pbcopy"},
+		},
+		{
+			name:      "Wayland",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			installed: []string{"wl-copy", "wl-paste", "xclip"},
+			read:      Command{Name: "wl-paste", Args: []string{"--no-newline"}},
+			write:     Command{Name: "wl-copy"},
+		},
+		{
+			name:      "X11 with xclip",
+			goos:      "linux",
+			installed: []string{"wl-copy", "wl-paste", "xclip"},
+			read:      Command{Name: "xclip", Args: []string{"-selection", "clipboard", "-o"}},
+			write:     Command{Name: "xclip", Args: []string{"-selection", "clipboard"}},
+		},
+		{
+			name:      "X11 with xsel",
+			goos:      "freebsd",
+			installed: []string{"xsel"},
+			read:      Command{Name: "xsel", Args: []string{"--clipboard", "--output"}},
+			write:     Command{Name: "xsel", Args: []string{"--clipboard", "--input"}},
+		},
+		{
+			name:      "Windows",
+			goos:      "windows",
+			installed: []string{"powershell", "clip"},
+			read:      Command{Name: "powershell", Args: []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}},
+			write:     Command{Name: "clip"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			var text string
+			var ran []Command
+			clipboard := fakeClipboard(test.goos, test.env, test.installed, &text, &ran)
+
+			result, err := (&WriteClipboardTool{clipboard}).Execute(context.Background(), map[string]interface{}{"text": "copied"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Content != "Copied 6 bytes to the clipboard." {
+				t.Errorf("write said %q", result.Content)
+			}
+			result, err = (&ReadClipboardTool{clipboard}).Execute(context.Background(), map[string]interface{}{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Content != "copied" {
+				t.Errorf("read %q", result.Content)
+			}
+			write := test.write
+			write.Stdin = "copied"
+			if len(ran) != 2 || !sameCommand(ran[0], write) || !sameCommand(ran[1], test.read) {
+				t.Errorf("ran %+v", ran)
+			}
+		})
+	}
+}
+
+func TestClipboardUnavailable(t *testing.T) {
+	var text string
+	var ran []Command
+	clipboard := fakeClipboard("linux", nil, nil, &text, &ran)
+	_, err := (&ReadClipboardTool{clipboard}).Execute(context.Background(), map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "no clipboard utility found (tried xclip, xsel)") {
+		t.Errorf("got %v", err)
+	}
+	if len(ran) > 0 {
+		t.Errorf("ran %+v", ran)
+	}
+}
+
+func sameCommand(a, b Command) bool {
+	return a.Name == b.Name && slices.Equal(a.Args, b.Args) && a.Stdin == b.Stdin
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxHelpBytes = 16 * 1024
+
+var (
+	commandWord = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+	overstrike  = regexp.MustCompile(".\b")
+)
+
+// CommandHelpTool returns a command's --help text (or its man page) so the
+// model can use correct flags.
+type CommandHelpTool struct {
+	Runner CommandRunner
+}
+
+func (this *CommandHelpTool) Name() string { return "command_help" }
+func (this *CommandHelpTool) Description() string {
+	return "Show the --help output (or man page) for a command, e.g. 'tar' or 'go build'"
+}
+func (this *CommandHelpTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"command": map[string]interface{}{
+				"type":        "string",
+				"description": "The command (and optional subcommand) to get help for",
+			},
+		},
+		"required": []string{"command"},
+	}
+}
+func (this *CommandHelpTool) RequiresPermission() bool { return false }
+func (this *CommandHelpTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	command, err := GetNonEmptyString(params, "command")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	words := strings.Fields(command)
+	for _, word := range words {
+		if !commandWord.MatchString(word) {
+			return ToolResult{}, fmt.Errorf("invalid command %q: only plain command and subcommand names are allowed", command)
+		}
+	}
+	runner := runnerOrDefault(this.Runner)
+
+	helpCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	help, err := runner(helpCtx, Command{Name: words[0], Args: append(words[1:], "--help")})
+	hasHelp := len(strings.TrimSpace(string(help))) > 0 && !errors.Is(err, exec.ErrNotFound)
+	if hasHelp && err == nil {
+		return ToolResult{Content: capHelp(string(help))}, nil
+	}
+
+	// A failed --help run may only have printed "unknown option", so the man
+	// page is preferred; its output is still better than nothing.
+	manCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	output, err := runner(manCtx, Command{Name: "man", Args: []string{"-P", "cat", strings.Join(words, "-")}})
+	if err == nil && len(strings.TrimSpace(string(output))) > 0 {
+		return ToolResult{Content: capHelp(overstrike.ReplaceAllString(string(output), ""))}, nil
+	}
+	if hasHelp {
+		return ToolResult{Content: capHelp(string(help))}, nil
+	}
+	return ToolResult{}, fmt.Errorf("no help available for %q (neither --help nor man produced output)", command)
+}
+
+func capHelp(text string) string {
+	if len(text) <= maxHelpBytes {
+		return text
+	}
+	return text[:maxHelpBytes] + fmt.Sprintf("\n[truncated: help text exceeds %d bytes]\n", maxHelpBytes)
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommandHelp(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		outputs map[string]string // "name args" to output; other commands fail
+		failing map[string]string // "name args" to output of a command that fails
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "help flag",
+			command: "go build",
+			outputs: map[string]string{"go build --help": "usage: go build [-o output] [build flags] [packages]\n"},
+			want:    "usage: go build [-o output]",
+		},
+		{
+			name:    "falls back to man",
+			command: "tar",
+			outputs: map[string]string{"man -P cat tar": "N\bNA\bAM\bME\bE\n     tar - an archiving utility\n"},
+			want:    "NAME\n     tar - an archiving utility",
+		},
+		{
+			name:    "failed help run falls back to man",
+			command: "tar",
+			failing: map[string]string{"tar --help": "tar: unrecognized option '--help'\n"},
+			outputs: map[string]string{"man -P cat tar": "NAME\n     tar - an archiving utility\n"},
+			want:    "tar - an archiving utility",
+		},
+		{
+			name:    "failed help run without a man page",
+			command: "mytool",
+			failing: map[string]string{"mytool --help": "usage: mytool [-v] file\n"},
+			outputs: map[string]string{},
+			want:    "usage: mytool [-v] file",
+		},
+		{
+			name:    "no help at all",
+			command: "mystery",
+			outputs: map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "refuses shell syntax",
+			command: "ls; rm -rf /",
+			outputs: map[string]string{},
+			wantErr: true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tool := &CommandHelpTool{Runner: func(ctx context.Context, command Command) ([]byte, error) {
+				line := strings.Join(append([]string{command.Name}, command.Args...), " ")
+				if output, ok := test.failing[line]; ok {
+					return []byte(output), errors.New("exit status 2")
+				}
+				output, ok := test.outputs[line]
+				if !ok {
+					return nil, errors.New("exit status 1")
+				}
+				return []byte(output), nil
+			}}
+			result, err := tool.Execute(context.Background(), map[string]interface{}{"command": test.command})
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", result.Content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(result.Content, test.want) {
+				t.Errorf("got %q, want it to contain %q", result.Content, test.want)
+			}
+		})
+	}
+}
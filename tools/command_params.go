@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// workingDirParam resolves the optional working_dir parameter to an absolute
+// directory, returning "" when it is absent.
+func workingDirParam(params map[string]interface{}) (string, error) {
+	dir, err := GetOptionalString(params, "working_dir", "")
+	if err != nil || dir == "" {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("working_dir %s does not exist", dir)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working_dir %s is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// withWorkingDirHeader prefixes a command's result with the directory it ran
+// in, when one was requested.
+func withWorkingDirHeader(dir, output string) string {
+	if dir == "" {
+		return output
+	}
+	return fmt.Sprintf("[working directory: %s]\n%s", dir, output)
+}
+
+// timeoutParam reads the optional timeout_seconds parameter, returning
+// fallback when it is absent or not positive.
+func timeoutParam(params map[string]interface{}, fallback time.Duration) (time.Duration, error) {
+	seconds, err := GetFloat(params, "timeout_seconds", 0)
+	if err != nil || seconds <= 0 {
+		return fallback, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envParam builds a command environment from the optional env (name -> value)
+// and clear_env parameters. The variables supplement the inherited
+// environment, overriding same-named ones, unless clear_env is true. It
+// returns nil, meaning "inherit", when neither parameter is given. Values are
+// passed to the process as-is (no shell expansion), so '=' and newlines are
+// kept literally; names must be plain identifiers and NUL bytes are rejected.
+func envParam(params map[string]interface{}) ([]string, error) {
+	clearEnv, err := GetBool(params, "clear_env", false)
+	if err != nil {
+		return nil, err
+	}
+	raw, present := params["env"]
+	if (!present || raw == nil) && !clearEnv {
+		return nil, nil
+	}
+	values, ok := raw.(map[string]interface{})
+	if raw != nil && !ok {
+		return nil, fmt.Errorf("env parameter must be an object mapping variable names to values")
+	}
+	var env []string
+	if !clearEnv {
+		env = os.Environ()
+	} else {
+		env = []string{} // empty but non-nil: nothing inherited
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !envName.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name %q", name)
+		}
+		var value string
+		switch typed := values[name].(type) {
+		case string:
+			value = typed
+		case float64, bool:
+			value = fmt.Sprint(typed)
+		default:
+			return nil, fmt.Errorf("environment variable %s must have a string value", name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return nil, fmt.Errorf("environment variable %s contains a NUL byte", name)
+		}
+		env = append(env, name+"="+value) // later entries win over inherited ones
+	}
+	return env, nil
+}
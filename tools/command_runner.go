@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// Command describes an external program invocation (no shell involved).
+type Command struct {
+	Dir   string
+	Name  string
+	Args  []string
+	Stdin string
+	Env   []string // nil inherits the environment (see envParam)
+}
+
+// CommandRunner runs a command and returns its combined output. Tools that shell
+// out to other programs accept one so tests and alternate platforms can supply their own.
+type CommandRunner func(ctx context.Context, command Command) ([]byte, error)
+
+// ExecRunner is the default CommandRunner, backed by os/exec.
+func ExecRunner(ctx context.Context, command Command) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...)
+	cmd.Dir = command.Dir
+	cmd.Env = command.Env
+	if command.Stdin != "" {
+		cmd.Stdin = strings.NewReader(command.Stdin)
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	return output.Bytes(), err
+}
+
+func runnerOrDefault(runner CommandRunner) CommandRunner {
+	if runner == nil {
+		return ExecRunner
+	}
+	return runner
+}
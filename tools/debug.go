@@ -0,0 +1,13 @@
+package tools
+
+import "log"
+
+// Verbose enables debug logging of tool internals through the standard log
+// package (set by the -verbose flag).
+var Verbose bool
+
+func debugf(format string, args ...interface{}) {
+	if Verbose {
+		log.Printf(format, args...)
+	}
+}
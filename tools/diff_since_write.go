@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DiffAgainstLastWriteTool shows how a file changed since a tool last wrote it,
+// so the model can notice edits made outside the conversation.
+type DiffAgainstLastWriteTool struct {
+	Sandbox
+	Session
+}
+
+func (this *DiffAgainstLastWriteTool) Name() string { return "diff_since_write" }
+func (this *DiffAgainstLastWriteTool) Description() string {
+	return "Show a diff between what was last written to a file (by write_file or modify_file in this session) and its current contents, revealing external edits"
+}
+func (this *DiffAgainstLastWriteTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to check",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+func (this *DiffAgainstLastWriteTool) RequiresPermission() bool { return false }
+func (this *DiffAgainstLastWriteTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	written, ok := this.lastWrite(path)
+	if !ok {
+		return ToolResult{Content: fmt.Sprintf("No prior write recorded for %s in this session.", path)}, nil
+	}
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ToolResult{Content: fmt.Sprintf("%s has been deleted since it was last written.", path)}, nil
+	}
+	if err != nil {
+		return ToolResult{}, err
+	}
+	diff := unifiedDiff(path+" (last write)", path+" (current)", written, string(current))
+	if diff == "" {
+		return ToolResult{Content: fmt.Sprintf("%s is unchanged since it was last written.", path)}, nil
+	}
+	this.markRead(path)
+	return ToolResult{Content: diff}, nil
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memorySession is a SessionState kept in maps.
+type memorySession struct {
+	read   map[string]bool
+	writes map[string]string
+}
+
+func newMemorySession() *memorySession {
+	return &memorySession{read: make(map[string]bool), writes: make(map[string]string)}
+}
+
+func (this *memorySession) WasRead(path string) bool         { return this.read[path] }
+func (this *memorySession) MarkRead(path string)             { this.read[path] = true }
+func (this *memorySession) RecordWrite(path, content string) { this.writes[path] = content }
+func (this *memorySession) LastWrite(path string) (string, bool) {
+	content, ok := this.writes[path]
+	return content, ok
+}
+func (this *memorySession) RecordEdit(path string, before []byte, existed bool) {}
+
+func TestDiffSinceWriteShowsExternalChange(t *testing.T) {
+	dir := t.TempDir()
+	session := newMemorySession()
+	write := &WriteFileTool{Sandbox: Sandbox{Root: dir}}
+	write.SetSession(session)
+	diff := &DiffAgainstLastWriteTool{Sandbox: Sandbox{Root: dir}}
+	diff.SetSession(session)
+	ctx := context.Background()
+
+	result, err := diff.Execute(ctx, map[string]interface{}{"path": "config.txt"})
+	if err != nil || !strings.Contains(result.Content, "No prior write recorded") {
+		t.Fatalf("before any write: %q, %v", result.Content, err)
+	}
+	if _, err := write.Execute(ctx, map[string]interface{}{"path": "config.txt", "content": "port=8080\nhost=localhost\n"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = diff.Execute(ctx, map[string]interface{}{"path": "config.txt"})
+	if err != nil || !strings.Contains(result.Content, "unchanged since it was last written") {
+		t.Fatalf("right after the write: %q, %v", result.Content, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.txt"), []byte("port=9090\nhost=localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = diff.Execute(ctx, map[string]interface{}{"path": "config.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, "-port=8080\n") || !strings.Contains(result.Content, "+port=9090\n") {
+		t.Errorf("the external change is missing from the diff:\n%s", result.Content)
+	}
+}
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvFileTool reads and updates .env-style (KEY=VALUE) files, preserving
+// comments and line order.
+type EnvFileTool struct {
+	Sandbox
+}
+
+func (this *EnvFileTool) Name() string { return "env_file" }
+func (this *EnvFileTool) Description() string {
+	return "Read, list, or set keys in a .env-style file (KEY=VALUE lines). Setting a key updates it in place or appends it, preserving comments and ordering."
+}
+func (this *EnvFileTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the .env file",
+			},
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"get", "set", "list"},
+				"description": "The operation to perform: get (read a key), set (write a key), or list (all keys)",
+			},
+			"key": map[string]interface{}{
+				"type":        "string",
+				"description": "The key to get or set (required for get and set)",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "The value to set (required for set)",
+			},
+		},
+		"required": []string{"path", "operation"},
+	}
+}
+func (this *EnvFileTool) RequiresPermission() bool { return false }
+func (this *EnvFileTool) RequiresPermissionFor(params map[string]interface{}) bool {
+	operation, _ := GetOptionalString(params, "operation", "")
+	return operation == "set"
+}
+func (this *EnvFileTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	operation, err := GetString(params, "operation")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	key, err := GetOptionalString(params, "key", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	switch operation {
+	case "list":
+		lines, err := readEnvLines(path)
+		if err != nil {
+			return ToolResult{}, err
+		}
+		var result strings.Builder
+		for _, line := range lines {
+			if name, _, ok := parseEnvLine(line); ok {
+				result.WriteString(name + "\n")
+			}
+		}
+		return ToolResult{Content: result.String()}, nil
+	case "get":
+		if key == "" {
+			return ToolResult{}, errors.New("key parameter is required for get")
+		}
+		lines, err := readEnvLines(path)
+		if err != nil {
+			return ToolResult{}, err
+		}
+		for _, line := range lines {
+			if name, value, ok := parseEnvLine(line); ok && name == key {
+				return ToolResult{Content: value}, nil
+			}
+		}
+		return ToolResult{}, fmt.Errorf("key %s not found in %s", key, path)
+	case "set":
+		if !isValidEnvKey(key) {
+			return ToolResult{}, fmt.Errorf("invalid key: %q", key)
+		}
+		value, err := GetString(params, "value")
+		if err != nil {
+			return ToolResult{}, err
+		}
+		if strings.ContainsAny(value, "\n\r") {
+			return ToolResult{}, errors.New("value must not contain newlines")
+		}
+		return this.set(path, key, value)
+	default:
+		return ToolResult{}, fmt.Errorf("unknown operation: %q (expected get, set, or list)", operation)
+	}
+}
+
+func (this *EnvFileTool) set(path, key, value string) (ToolResult, error) {
+	lines, err := readEnvLines(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ToolResult{}, err
+	}
+	assignment := key + "=" + quoteEnvValue(value)
+	updated := false
+	for i, line := range lines {
+		if name, _, ok := parseEnvLine(line); ok && name == key {
+			if strings.HasPrefix(strings.TrimSpace(line), "export ") {
+				lines[i] = "export " + assignment
+			} else {
+				lines[i] = assignment
+			}
+			updated = true
+		}
+	}
+	if !updated {
+		lines = append(lines, assignment)
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return ToolResult{}, err
+	}
+	result := ToolResult{
+		Content:  fmt.Sprintf("Added %s to %s", key, path),
+		Metadata: map[string]interface{}{MetaPath: path, MetaBytesWritten: len(content)},
+	}
+	if updated {
+		result.Content = fmt.Sprintf("Updated %s in %s", key, path)
+	}
+	return result, nil
+}
+
+func readEnvLines(path string) ([]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSuffix(string(raw), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// parseEnvLine extracts the key and (unquoted) value from an assignment line,
+// ignoring blank lines and comments.
+func parseEnvLine(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, value, ok = strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !isValidEnvKey(key) {
+		return "", "", false
+	}
+	value = strings.TrimSpace(value)
+	if end := closingQuote(value); end > 0 {
+		if unquoted, err := strconv.Unquote(value[:end+1]); value[0] == '"' && err == nil {
+			return key, unquoted, true
+		}
+		return key, value[1:end], true
+	}
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	return key, value, true
+}
+
+// closingQuote returns the index of the quote closing the quoted value at the
+// start of value (which may be followed by a comment), or -1.
+func closingQuote(value string) int {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return -1
+	}
+	for i := 1; i < len(value); i++ {
+		if value[0] == '"' && value[i] == '\\' {
+			i++ // escaped character
+			continue
+		}
+		if value[i] == value[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+func quoteEnvValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t#\"'\\$`") {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func isValidEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		if c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || (i > 0 && '0' <= c && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
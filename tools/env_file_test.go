@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testEnvFile = `# database settings
+DB_HOST=localhost
+export DB_PORT=5432
+
+# secrets
+API_KEY="abc 123" # quoted
+`
+
+func TestEnvFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string // the result
+		wantErr bool
+		file    string // the file afterwards (unchanged when empty)
+	}{
+		{
+			name:   "get existing key",
+			params: map[string]interface{}{"operation": "get", "key": "DB_PORT"},
+			want:   "5432",
+		},
+		{
+			name:   "get quoted value",
+			params: map[string]interface{}{"operation": "get", "key": "API_KEY"},
+			want:   "abc 123",
+		},
+		{
+			name:    "get missing key",
+			params:  map[string]interface{}{"operation": "get", "key": "MISSING"},
+			wantErr: true,
+		},
+		{
+			name:   "list keys",
+			params: map[string]interface{}{"operation": "list"},
+			want:   "DB_HOST\nDB_PORT\nAPI_KEY\n",
+		},
+		{
+			name:   "set updates in place",
+			params: map[string]interface{}{"operation": "set", "key": "DB_PORT", "value": "6543"},
+			want:   "Updated DB_PORT in ",
+			file:   "# database settings\nDB_HOST=localhost\nexport DB_PORT=6543\n\n# secrets\nAPI_KEY=\"abc 123\" # quoted\n",
+		},
+		{
+			name:   "set appends a new key and keeps comments",
+			params: map[string]interface{}{"operation": "set", "key": "DEBUG", "value": "on and off"},
+			want:   "Added DEBUG to ",
+			file:   testEnvFile + "DEBUG=\"on and off\"\n",
+		},
+		{
+			name:    "set refuses an invalid key",
+			params:  map[string]interface{}{"operation": "set", "key": "1BAD", "value": "x"},
+			wantErr: true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".env")
+			if err := os.WriteFile(path, []byte(testEnvFile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tool := &EnvFileTool{Sandbox: Sandbox{Root: dir}}
+			test.params["path"] = ".env"
+			result, err := tool.Execute(context.Background(), test.params)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", result.Content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.file == "" && result.Content != test.want {
+				t.Errorf("got %q, want %q", result.Content, test.want)
+			}
+			if test.file != "" && result.Content != test.want+path {
+				t.Errorf("got %q, want %q", result.Content, test.want+path)
+			}
+			content, _ := os.ReadFile(path)
+			if want := cmp.Or(test.file, testEnvFile); string(content) != want {
+				t.Errorf("file is now\n%s\nwant\n%s", content, want)
+			}
+		})
+	}
+}
+
+func TestEnvFileStaysInSandbox(t *testing.T) {
+	tool := &EnvFileTool{Sandbox: Sandbox{Root: t.TempDir()}}
+	params := map[string]interface{}{"path": "../.env", "operation": "set", "key": "A", "value": "b"}
+	if _, err := tool.Execute(context.Background(), params); err == nil {
+		t.Error("expected a path outside the sandbox to be refused")
+	}
+}
@@ -1,39 +1,65 @@
 package tools
 
 import (
-	"fmt"
-	"os/exec"
+	"context"
+	"time"
 )
 
-// ExecutePythonTool implements Python script execution
-type ExecutePythonTool struct{}
+const defaultInstallTimeout = 5 * time.Minute
+
+// ExecutePythonTool implements Python script execution on top of ScriptTool,
+// adding installation of third-party packages.
+type ExecutePythonTool struct {
+	CacheDir string // where virtualenvs for requirements are kept (default: the user cache directory)
+}
 
 func (this *ExecutePythonTool) Name() string { return "execute_python" }
 func (this *ExecutePythonTool) Description() string {
-	return "Execute a Python script and return its output"
+	return "Execute a Python script and return its output. Third-party packages listed in requirements are installed into a cached virtualenv first"
 }
 func (this *ExecutePythonTool) Parameters() map[string]interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"script": map[string]interface{}{
-				"type":        "string",
-				"description": "The Python code to execute",
-			},
-		},
-		"required": []string{"script"},
+	parameters := this.script(nil).Parameters()
+	properties := parameters["properties"].(map[string]interface{})
+	properties["args"].(map[string]interface{})["description"] = "Command-line arguments for the script, available as sys.argv[1:] (optional)"
+	properties["timeout_seconds"].(map[string]interface{})["description"] = "Kill the script (and any package installation) after this many seconds (optional, default 30, or 300 with requirements)"
+	properties["requirements"] = map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "pip packages the script needs, e.g. [\"pandas\", \"requests>=2.31\"] (optional); the environment is reused by later calls with the same set",
 	}
+	return parameters
 }
 func (this *ExecutePythonTool) RequiresPermission() bool { return true }
-func (this *ExecutePythonTool) Execute(params map[string]interface{}) (string, error) {
-	script, ok := params["script"].(string)
-	if !ok || script == "" {
-		return "", fmt.Errorf("script parameter must be a non-empty string")
-	}
-	cmd := exec.Command("python3", "-c", script)
-	output, err := cmd.CombinedOutput()
+func (this *ExecutePythonTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	requirements, err := requirementsParam(params)
 	if err != nil {
-		return string(output), fmt.Errorf("python execution failed: %v\n%s", err, string(output))
+		return ToolResult{}, err
+	}
+	if _, ok := params["timeout_seconds"]; !ok && len(requirements) > 0 {
+		params = withDefault(params, "timeout_seconds", defaultInstallTimeout.Seconds())
+	}
+	return this.script(requirements).Execute(ctx, params)
+}
+
+func (this *ExecutePythonTool) script(requirements []string) *ScriptTool {
+	script := NewScriptTool("python3", nil, this.Name(), this.Description())
+	script.FileExtension = ".py" // tracebacks then have real line numbers, and project modules import
+	if len(requirements) > 0 {
+		script.setupHeading = "pip install"
+		script.setup = func(ctx context.Context, params map[string]interface{}) (string, string, error) {
+			return pythonVenv(ctx, this.CacheDir, requirements)
+		}
+	}
+	return script
+}
+
+// withDefault returns a copy of params with name set to value, leaving the
+// caller's map (which may belong to the conversation history) untouched.
+func withDefault(params map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(params)+1)
+	for key, existing := range params {
+		result[key] = existing
 	}
-	return string(output), nil
+	result[name] = value
+	return result
 }
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecutePythonImportsProjectModules(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 isn't installed")
+	}
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{"mymod.py": "GREETING = 'hello from mymod'\n"})
+	script := "import mymod\nprint(mymod.GREETING)\n"
+	cases := map[string]struct {
+		cwd    string
+		params map[string]interface{}
+	}{
+		"working_dir":       {cwd: t.TempDir(), params: map[string]interface{}{"script": script, "working_dir": project}},
+		"current directory": {cwd: project, params: map[string]interface{}{"script": script}},
+	}
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Chdir(test.cwd)
+			tool := &ExecutePythonTool{}
+			result, err := tool.Execute(context.Background(), test.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(result.Content, "hello from mymod") {
+				t.Errorf("unexpected output %q", result.Content)
+			}
+			if leftover, _ := filepath.Glob(filepath.Join(project, ".cli-ai-agent-*")); len(leftover) > 0 {
+				t.Errorf("the script file was left behind: %q", leftover)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultFetchBytes = 64 * 1024
+	maxFetchBytes     = 1024 * 1024
+	fetchTimeout      = 30 * time.Second
+)
+
+// FetchURLTool reads a web page or other text resource over HTTP(S).
+type FetchURLTool struct {
+	Client       *http.Client // nil uses a default client
+	AllowedHosts []string     // when set, only these hosts (and their subdomains) may be fetched (-allowed-hosts)
+}
+
+func (this *FetchURLTool) Name() string { return "fetch_url" }
+func (this *FetchURLTool) Description() string {
+	description := "Fetch an http(s) URL with GET and return the body as text, e.g. documentation linked from an error message or a raw source file. " +
+		"HTML is reduced to its text unless raw_html is true. Only text responses (text/*, JSON, XML, JavaScript) are returned."
+	if len(this.AllowedHosts) > 0 {
+		description += " Only these hosts may be fetched: " + strings.Join(this.AllowedHosts, ", ") + "."
+	}
+	return description
+}
+func (this *FetchURLTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "The http or https URL to fetch",
+			},
+			"max_bytes": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Read at most this many bytes of the body (optional, default %d, at most %d)", defaultFetchBytes, maxFetchBytes),
+			},
+			"raw_html": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Return HTML as is instead of reducing it to text (optional, default false)",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+func (this *FetchURLTool) RequiresPermission() bool { return true }
+func (this *FetchURLTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	raw, err := GetNonEmptyString(params, "url")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	target, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := this.permitted(target); err != nil {
+		return ToolResult{}, err
+	}
+	maxBytes, err := GetInt(params, "max_bytes", defaultFetchBytes)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultFetchBytes
+	}
+	maxBytes = min(maxBytes, maxFetchBytes)
+	rawHTML, err := GetBool(params, "raw_html", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	request.Header.Set("Accept", "text/html, text/plain, application/json, */*;q=0.5")
+	client := http.Client{Timeout: fetchTimeout}
+	if this.Client != nil {
+		client = *this.Client
+	}
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return this.permitted(next.URL) // a redirect mustn't lead somewhere a direct request couldn't
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	contentType := response.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if contentType != "" && !isTextMedia(mediaType) {
+		return ToolResult{}, fmt.Errorf("%s returned %s, not text", target, mediaType)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, int64(maxBytes)+1))
+	if err != nil {
+		return ToolResult{}, err
+	}
+	truncated := len(body) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1] // don't split a multi-byte character
+		}
+	}
+	if !utf8.Valid(body) {
+		return ToolResult{}, fmt.Errorf("%s returned a body that is not UTF-8 text", target)
+	}
+	text := string(body)
+	if mediaType == "text/html" && !rawHTML {
+		text = htmlToText(text)
+	}
+
+	var result strings.Builder
+	_, _ = fmt.Fprintf(&result, "[GET %s: %s, %s]\n%s", response.Request.URL, response.Status, mediaType, text)
+	if truncated {
+		_, _ = fmt.Fprintf(&result, "\n\n[truncated: the body exceeds max_bytes %d]\n", maxBytes)
+	}
+	content := result.String()
+	if response.StatusCode/100 != 2 {
+		return ToolResult{Content: content}, fmt.Errorf("%s returned %s\n%s", target, response.Status, content)
+	}
+	return ToolResult{Content: content, Metadata: map[string]interface{}{MetaTruncated: truncated}}, nil
+}
+
+// permitted refuses URLs that aren't http(s) or whose host isn't allowed.
+func (this *FetchURLTool) permitted(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("refusing to fetch %q: only http and https URLs are allowed", target.String())
+	}
+	host := strings.ToLower(target.Hostname())
+	if host == "" {
+		return fmt.Errorf("invalid url %q: no host", target.String())
+	}
+	if len(this.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range this.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to fetch from %s: it is not one of the allowed hosts (%s)", host, strings.Join(this.AllowedHosts, ", "))
+}
+
+func isTextMedia(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/javascript" || mediaType == "application/x-sh"
+}
+
+var (
+	htmlInvisible  = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)\b.*?</(script|style|noscript|svg|head)\s*>|<!--.*?-->`)
+	htmlBlockTag   = regexp.MustCompile(`(?i)</?(p|div|br|hr|h[1-6]|li|ul|ol|tr|table|pre|section|article|header|footer|nav|blockquote)\b[^>]*>`)
+	htmlTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlBlankLines = regexp.MustCompile(`\n\s*\n(\s*\n)+`)
+)
+
+// htmlToText roughly reduces an HTML document to its readable text: scripts,
+// styles and comments are dropped, block elements become line breaks, other
+// tags are removed and entities are decoded.
+func htmlToText(document string) string {
+	text := htmlInvisible.ReplaceAllString(document, "")
+	text = htmlBlockTag.ReplaceAllString(text, "\n")
+	text = htmlTag.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(htmlBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileOpsTool deletes, moves (renames) and copies files and directories.
+type FileOpsTool struct {
+	Sandbox
+}
+
+func (this *FileOpsTool) Name() string { return "file_ops" }
+func (this *FileOpsTool) Description() string {
+	return "Delete, move (rename) or copy a file or directory. Directories are only deleted or copied with recursive=true. " +
+		"move and copy never overwrite: when dest is an existing directory the source is placed inside it, and any other existing dest is refused."
+}
+func (this *FileOpsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"delete", "move", "copy"},
+				"description": "What to do with source",
+			},
+			"source": map[string]interface{}{
+				"type":        "string",
+				"description": "The file or directory to delete, move or copy",
+			},
+			"dest": map[string]interface{}{
+				"type":        "string",
+				"description": "Where to move or copy source to (required for move and copy); missing parent directories are created",
+			},
+			"recursive": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Allow deleting or copying a directory with everything in it (optional, default false)",
+			},
+		},
+		"required": []string{"operation", "source"},
+	}
+}
+func (this *FileOpsTool) RequiresPermission() bool { return true }
+func (this *FileOpsTool) PermissionWarning(params map[string]interface{}) string {
+	operation, _ := GetString(params, "operation")
+	if operation != "delete" {
+		return ""
+	}
+	source, _ := GetString(params, "source")
+	if recursive, _ := GetBool(params, "recursive", false); recursive {
+		return fmt.Sprintf("This permanently deletes %s and, if it is a directory, everything under it.", source)
+	}
+	return fmt.Sprintf("This permanently deletes %s.", source)
+}
+func (this *FileOpsTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	operation, err := GetString(params, "operation")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	source, err := GetNonEmptyString(params, "source")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	recursive, err := GetBool(params, "recursive", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	source, err = this.resolveEntry(source)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	info, err := os.Lstat(source)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	switch operation {
+	case "delete":
+		return this.delete(source, info, recursive)
+	case "move":
+	case "copy":
+		if info.Mode()&os.ModeSymlink != 0 {
+			// copy what the link points to, as long as that is inside the sandbox
+			if source, err = this.Resolve(source); err != nil {
+				return ToolResult{}, err
+			}
+			if info, err = os.Stat(source); err != nil {
+				return ToolResult{}, err
+			}
+		}
+	default:
+		return ToolResult{}, fmt.Errorf("invalid operation %q (expected delete, move, or copy)", operation)
+	}
+	dest, err := GetNonEmptyString(params, "dest")
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("dest parameter is required for %s", operation)
+	}
+	dest, err = this.resolveEntry(dest)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if existing, err := os.Stat(dest); err == nil && existing.IsDir() {
+		dest = filepath.Join(dest, filepath.Base(source))
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return ToolResult{}, fmt.Errorf("%s already exists; delete it first if it should be replaced", dest)
+	}
+	if info.IsDir() && (Sandbox{Root: source}).contains(dest) {
+		return ToolResult{}, fmt.Errorf("cannot %s %s into itself", operation, source)
+	}
+	var note string
+	created, err := createParentDirs(dest)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if created != "" {
+		note = fmt.Sprintf("[created directory %s]\n", created)
+	}
+	if operation == "move" {
+		if err := os.Rename(source, dest); err != nil {
+			return ToolResult{}, err
+		}
+		return ToolResult{Content: fmt.Sprintf("%sMoved %s to %s", note, source, dest), Metadata: map[string]interface{}{MetaPath: dest}}, nil
+	}
+	if info.IsDir() {
+		if !recursive {
+			return ToolResult{}, fmt.Errorf("%s is a directory; set recursive=true to copy it and everything in it", source)
+		}
+		err = os.CopyFS(dest, os.DirFS(source))
+	} else {
+		err = copyFile(source, dest, info.Mode().Perm())
+	}
+	if err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: fmt.Sprintf("%sCopied %s to %s", note, source, dest), Metadata: map[string]interface{}{MetaPath: dest}}, nil
+}
+
+func (this *FileOpsTool) delete(path string, info os.FileInfo, recursive bool) (ToolResult, error) {
+	if filepath.Dir(path) == path || path == this.Root {
+		return ToolResult{}, fmt.Errorf("refusing to delete %s", path)
+	}
+	if info.IsDir() {
+		if !recursive {
+			return ToolResult{}, fmt.Errorf("%s is a directory; set recursive=true to delete it and everything in it", path)
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return ToolResult{}, err
+		}
+		return ToolResult{Content: fmt.Sprintf("Deleted directory %s", path), Metadata: map[string]interface{}{MetaPath: path}}, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: fmt.Sprintf("Deleted %s", path), Metadata: map[string]interface{}{MetaPath: path}}, nil
+}
+
+// resolveEntry is Resolve for a path that is itself operated on: only its
+// parent's symbolic links are followed, so deleting or moving a link affects
+// the link rather than what it points to.
+func (this *FileOpsTool) resolveEntry(path string) (string, error) {
+	if this.Root != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(this.Root, path)
+	}
+	path = filepath.Clean(path)
+	dir, err := this.Resolve(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// copyFile copies the regular file source to the new file dest.
+func copyFile(source, dest string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
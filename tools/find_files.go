@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+const defaultFindResults = 200
+
+// FindFilesTool lists the files or directories under a directory whose names
+// match a glob.
+type FindFilesTool struct {
+	Sandbox
+}
+
+func (this *FindFilesTool) Name() string { return "find_files" }
+func (this *FindFilesTool) Description() string {
+	return "Find files or directories by name under a directory and list their paths relative to it, one per line. " +
+		"Much cheaper than listing the whole tree when you know roughly what you're looking for. " +
+		"Dot-directories (such as .git) are skipped, as are entries matched by .gitignore files unless respect_gitignore is false; symbolic links are listed but not followed."
+}
+func (this *FindFilesTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name_pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Glob matched against each base name, e.g. '*_test.go', 'Makefile' or 'config.*'",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory to search (optional, default '.')",
+			},
+			"type": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"file", "dir"},
+				"description": "Only list files or only directories (optional, default both)",
+			},
+			"max_results": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("List at most this many paths (optional, default %d)", defaultFindResults),
+			},
+			"respect_gitignore": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Skip files and directories matched by .gitignore files found in the tree (optional, default true)",
+			},
+		},
+		"required": []string{"name_pattern"},
+	}
+}
+func (this *FindFilesTool) RequiresPermission() bool { return false }
+func (this *FindFilesTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	pattern, err := GetNonEmptyString(params, "name_pattern")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return ToolResult{}, fmt.Errorf("invalid name_pattern %q: %v", pattern, err)
+	}
+	kind, err := GetOptionalString(params, "type", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if kind != "" && kind != "file" && kind != "dir" {
+		return ToolResult{}, fmt.Errorf("invalid type %q (expected file or dir)", kind)
+	}
+	root, err := GetOptionalString(params, "path", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	maxResults, err := GetInt(params, "max_results", defaultFindResults)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if maxResults <= 0 {
+		maxResults = defaultFindResults
+	}
+	ignore := respectGitignore(params)
+
+	results := newCappedResults(maxResults)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			relative, _ := filepath.Rel(root, path)
+			results.Skip(filepath.ToSlash(relative)) // e.g. a directory without read permission
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(root, path)
+		relative = filepath.ToSlash(relative)
+		if relative == "." {
+			return nil
+		}
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".") || ignore.ignored(relative, true) {
+				return filepath.SkipDir
+			}
+			ignore.enter(path, relative)
+		} else if ignore.ignored(relative, false) {
+			return nil
+		}
+		if kind == "file" && entry.IsDir() || kind == "dir" && !entry.IsDir() {
+			return nil
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); matched {
+			if entry.IsDir() {
+				relative += "/"
+			}
+			results.Add(relative)
+		}
+		return nil
+	})
+	if err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: results.String("paths"), Metadata: map[string]interface{}{MetaTruncated: results.Truncated()}}, nil
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":             "",
+		"main_test.go":        "",
+		"cmd/tool/main.go":    "",
+		".git/hooks/x.go":     "",
+		"docs/main.go.md":     "",
+		"main/placeholder.md": "",
+	})
+	if err := os.Symlink(dir, filepath.Join(dir, "cmd", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	tool := &FindFilesTool{Sandbox: Sandbox{Root: dir}}
+	cases := []struct {
+		params map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"name_pattern": "main.go"}, "cmd/tool/main.go\nmain.go\n\n[2 paths]\n"},
+		{map[string]interface{}{"name_pattern": "main*", "type": "dir"}, "main/\n\n[1 paths]\n"},
+		{map[string]interface{}{"name_pattern": "*_test.go", "path": "cmd"}, "No paths found."},
+		{map[string]interface{}{"name_pattern": "main*", "type": "file", "max_results": 1}, "cmd/tool/main.go\n\n[truncated: showing 1 of 4 paths; narrow the search to see the rest]\n"},
+	}
+	for _, test := range cases {
+		result, err := tool.Execute(context.Background(), test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Content != test.want {
+			t.Errorf("%v: got %q, want %q", test.params, result.Content, test.want)
+		}
+	}
+}
+
+func TestFindFilesSkipsUnreadableDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a/main.go":      "",
+		"locked/main.go": "",
+	})
+	unreadableDir(t, dir, "locked")
+	tool := &FindFilesTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"name_pattern": "*.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a/main.go\n\n[1 paths]\n\n[skipped 1 unreadable entries: locked]\n"; result.Content != want {
+		t.Errorf("got %q, want %q", result.Content, want)
+	}
+}
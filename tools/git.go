@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGitLogCount = 20
+	maxGitOutputBytes  = 64 * 1024
+)
+
+// GitTool runs read-only git commands (status, diff, log and show). Nothing it
+// runs changes the repository, so it needs no permission; git_reset is the
+// tool for rewriting history.
+type GitTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *GitTool) Name() string { return "git" }
+func (this *GitTool) Description() string {
+	return "Inspect the git repository without changing it: 'status' lists changed files, 'diff' shows unstaged changes (or staged ones with staged=true, or changes since ref), " +
+		"'log' lists recent commits and 'show' shows a commit (default HEAD). Prefer this to running git through run_shell_command."
+}
+func (this *GitTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"status", "diff", "log", "show"},
+				"description": "The git command to run",
+			},
+			"ref": map[string]interface{}{
+				"type":        "string",
+				"description": "Commit, branch or range: diff compares against it, log starts from it, show shows it (optional), e.g. 'HEAD~3' or 'main..HEAD'",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Limit the output to this file or directory (optional)",
+			},
+			"staged": map[string]interface{}{
+				"type":        "boolean",
+				"description": "For diff, show staged changes instead of unstaged ones (optional, default false)",
+			},
+			"max_count": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("For log, list at most this many commits (optional, default %d)", defaultGitLogCount),
+			},
+			"working_dir": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory inside the repository to run git in (optional, defaults to the current directory)",
+			},
+		},
+		"required": []string{"operation"},
+	}
+}
+func (this *GitTool) RequiresPermission() bool { return false }
+func (this *GitTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	args, err := gitArgs(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	dir, err := GetOptionalString(params, "working_dir", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if dir == "" {
+		dir = this.Root
+	} else if dir, err = this.Resolve(dir); err != nil {
+		return ToolResult{}, err
+	} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ToolResult{}, fmt.Errorf("working_dir %s is not a directory", dir)
+	}
+	if path, _ := GetOptionalString(params, "path", ""); path != "" && this.Root != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := this.Resolve(path); err != nil {
+			return ToolResult{}, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	output, err := runnerOrDefault(this.Runner)(ctx, Command{Dir: dir, Name: "git", Args: args})
+	content := string(output)
+	truncated := len(content) > maxGitOutputBytes
+	if truncated {
+		content = content[:strings.LastIndex(content[:maxGitOutputBytes], "\n")+1] +
+			fmt.Sprintf("\n[truncated: the output exceeds %d bytes; narrow it with path or ref]\n", maxGitOutputBytes)
+	}
+	if err != nil {
+		return ToolResult{Content: content}, fmt.Errorf("git %s failed: %v\n%s", args[0], err, content)
+	}
+	if strings.TrimSpace(content) == "" {
+		content = fmt.Sprintf("(git %s printed nothing)", args[0])
+	}
+	return ToolResult{Content: content, Metadata: map[string]interface{}{MetaTruncated: truncated}}, nil
+}
+
+// gitArgs builds the arguments for the requested operation. A ref can't start
+// with '-', so the model can't smuggle in options (such as --output) that
+// would write files.
+func gitArgs(params map[string]interface{}) ([]string, error) {
+	operation, err := GetString(params, "operation")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := GetOptionalString(params, "ref", "")
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid ref %q", ref)
+	}
+	path, err := GetOptionalString(params, "path", "")
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	switch operation {
+	case "status":
+		args = []string{"status", "--short", "--branch"}
+		ref = "" // status has no ref
+	case "diff":
+		args = []string{"diff", "--no-ext-diff", "--no-color"}
+		staged, err := GetBool(params, "staged", false)
+		if err != nil {
+			return nil, err
+		}
+		if staged {
+			args = append(args, "--cached")
+		}
+	case "log":
+		count, err := GetInt(params, "max_count", defaultGitLogCount)
+		if err != nil {
+			return nil, err
+		}
+		if count <= 0 {
+			count = defaultGitLogCount
+		}
+		args = []string{"log", "--no-color", fmt.Sprintf("--max-count=%d", count), "--format=%h %ad %an: %s", "--date=short"}
+	case "show":
+		args = []string{"show", "--no-ext-diff", "--no-color", "--stat", "--patch"}
+		if ref == "" {
+			ref = "HEAD"
+		}
+	default:
+		return nil, fmt.Errorf("invalid operation %q (expected status, diff, log, or show)", operation)
+	}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	return args, nil
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GitInfoTool summarizes the state of the git repository in one call.
+type GitInfoTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *GitInfoTool) Name() string { return "git_info" }
+func (this *GitInfoTool) Description() string {
+	return "Summarize the current git repository: branch, upstream, ahead/behind counts, last commit, and whether the working tree is dirty"
+}
+func (this *GitInfoTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+func (this *GitInfoTool) RequiresPermission() bool { return false }
+func (this *GitInfoTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	info, err := gatherGitInfo(ctx, runnerOrDefault(this.Runner), this.Root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: info.String()}, nil
+}
+
+type gitInfo struct {
+	Branch     string
+	Upstream   string
+	Ahead      string
+	Behind     string
+	LastCommit string
+	Changed    int
+	Untracked  int
+}
+
+func (this gitInfo) Dirty() bool { return this.Changed+this.Untracked > 0 }
+
+func (this gitInfo) String() string {
+	var result strings.Builder
+	_, _ = fmt.Fprintf(&result, "branch: %s\n", this.Branch)
+	if this.Upstream == "" {
+		result.WriteString("upstream: (none)\n")
+	} else {
+		_, _ = fmt.Fprintf(&result, "upstream: %s (ahead %s, behind %s)\n", this.Upstream, this.Ahead, this.Behind)
+	}
+	_, _ = fmt.Fprintf(&result, "last commit: %s\n", this.LastCommit)
+	if this.Dirty() {
+		_, _ = fmt.Fprintf(&result, "dirty: yes (%d changed, %d untracked)\n", this.Changed, this.Untracked)
+	} else {
+		result.WriteString("dirty: no\n")
+	}
+	return result.String()
+}
+
+func gatherGitInfo(ctx context.Context, runner CommandRunner, dir string) (info gitInfo, err error) {
+	git := func(args ...string) (string, error) {
+		output, err := runner(ctx, Command{Dir: dir, Name: "git", Args: args})
+		return strings.TrimSpace(string(output)), err
+	}
+	if inside, err := git("rev-parse", "--is-inside-work-tree"); err != nil || inside != "true" {
+		return info, fmt.Errorf("not a git repository")
+	}
+	status, err := git("status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return info, fmt.Errorf("git status failed: %v\n%s", err, status)
+	}
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			info.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			info.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			counts := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(counts) == 2 {
+				info.Ahead = strings.TrimPrefix(counts[0], "+")
+				info.Behind = strings.TrimPrefix(counts[1], "-")
+			}
+		case strings.HasPrefix(line, "? "):
+			info.Untracked++
+		case line != "" && !strings.HasPrefix(line, "#"):
+			info.Changed++
+		}
+	}
+	if info.Upstream != "" && info.Ahead == "" {
+		info.Ahead, info.Behind = "?", "?"
+	}
+	info.LastCommit, err = git("log", "-1", "--format=%h %s (%an, %ar)")
+	if err != nil {
+		info.LastCommit = "(no commits yet)"
+	}
+	return info, nil
+}
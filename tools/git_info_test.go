@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitInfo(t *testing.T) {
+	dir := newGitRepo(t, "first commit")
+	tool := &GitInfoTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"branch: main\n", "upstream: (none)\n", "last commit: ", "first commit", "dirty: no\n"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("clean repository: %q missing from\n%s", want, result.Content)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "filea.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, "dirty: yes (1 changed, 1 untracked)\n") {
+		t.Errorf("dirty repository:\n%s", result.Content)
+	}
+}
+
+func TestGitInfoOutsideRepository(t *testing.T) {
+	tool := &GitInfoTool{Sandbox: Sandbox{Root: t.TempDir()}}
+	if _, err := tool.Execute(context.Background(), nil); err == nil || err.Error() != "not a git repository" {
+		t.Errorf("got %v, want not a git repository", err)
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GitResetTool moves the current branch back a number of commits in the local
+// repository. It never touches remotes, and refuses a hard reset unless forced.
+type GitResetTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *GitResetTool) Name() string { return "git_reset" }
+func (this *GitResetTool) Description() string {
+	return "Undo the last N local git commits with 'git reset'. Mode 'soft' keeps changes staged, 'mixed' keeps them unstaged; 'hard' discards them and requires force=true. Never touches remotes."
+}
+func (this *GitResetTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"mode": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"soft", "mixed", "hard"},
+				"description": "Reset mode (optional, default soft)",
+			},
+			"count": map[string]interface{}{
+				"type":        "number",
+				"description": "Number of commits to move back (optional, default 1)",
+			},
+			"force": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Required to allow mode 'hard', which discards changes",
+			},
+		},
+	}
+}
+func (this *GitResetTool) RequiresPermission() bool { return true }
+func (this *GitResetTool) PermissionWarning(params map[string]interface{}) string {
+	mode, count, _ := gitResetArgs(params)
+	warning := fmt.Sprintf("This rewrites local history: the current branch moves back %d commit(s) (git reset --%s).", count, mode)
+	if mode == "hard" {
+		warning += " All uncommitted changes and the changes in those commits will be DISCARDED."
+	}
+	return warning
+}
+func (this *GitResetTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	mode, count, err := gitResetArgs(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	force, err := GetBool(params, "force", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if mode == "hard" && !force {
+		return ToolResult{}, errors.New("refusing to run 'git reset --hard' without force=true (it discards changes); use mode 'soft' or 'mixed' instead")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	runner := runnerOrDefault(this.Runner)
+	target := fmt.Sprintf("HEAD~%d", count)
+	if _, err := runner(ctx, Command{Dir: this.Root, Name: "git", Args: []string{"rev-parse", "--verify", "--quiet", target}}); err != nil {
+		return ToolResult{}, fmt.Errorf("cannot move back %d commit(s): %s does not exist (or this is not a git repository)", count, target)
+	}
+	output, err := runner(ctx, Command{Dir: this.Root, Name: "git", Args: []string{"reset", "--" + mode, target}})
+	if err != nil {
+		return ToolResult{Content: string(output)}, fmt.Errorf("git reset failed: %v\n%s", err, string(output))
+	}
+	head, _ := runner(ctx, Command{Dir: this.Root, Name: "git", Args: []string{"log", "-1", "--oneline"}})
+	status, _ := runner(ctx, Command{Dir: this.Root, Name: "git", Args: []string{"status", "--short"}})
+	return ToolResult{Content: fmt.Sprintf("Reset (%s) %d commit(s). HEAD is now: %s\n%s%s", mode, count, strings.TrimSpace(string(head)), output, status)}, nil
+}
+
+func gitResetArgs(params map[string]interface{}) (mode string, count int, err error) {
+	mode, err = GetOptionalString(params, "mode", "")
+	if err != nil {
+		return "", 0, err
+	}
+	if mode == "" {
+		mode = "soft"
+	}
+	if mode != "soft" && mode != "mixed" && mode != "hard" {
+		return "", 0, fmt.Errorf("invalid mode %q (expected soft, mixed, or hard)", mode)
+	}
+	count, err = GetInt(params, "count", 1)
+	if err != nil {
+		return "", 0, err
+	}
+	if count < 1 {
+		return "", 0, errors.New("count must be at least 1")
+	}
+	return mode, count, nil
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newGitRepo creates a repository in a temporary directory with one commit
+// per message, each adding a file named after the commit's position.
+func newGitRepo(t *testing.T, messages ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	for i, message := range messages {
+		name := filepath.Join(dir, "file"+string(rune('a'+i))+".txt")
+		if err := os.WriteFile(name, []byte(message+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "--quiet", "--message", message)
+	}
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	output, err := ExecRunner(context.Background(), Command{Dir: dir, Name: "git", Args: args})
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestGitResetSoft(t *testing.T) {
+	dir := newGitRepo(t, "first", "second")
+	tool := &GitResetTool{Sandbox: Sandbox{Root: dir}}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"mode": "soft", "count": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if subject := runGit(t, dir, "log", "-1", "--format=%s"); subject != "first" {
+		t.Errorf("HEAD is %q, want first", subject)
+	}
+	if staged := runGit(t, dir, "diff", "--cached", "--name-only"); staged != "fileb.txt" {
+		t.Errorf("staged %q, want the undone commit's file", staged)
+	}
+}
+
+func TestGitResetRefusesHardWithoutForce(t *testing.T) {
+	dir := newGitRepo(t, "first", "second")
+	tool := &GitResetTool{Sandbox: Sandbox{Root: dir}}
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"mode": "hard"})
+	if err == nil || !strings.Contains(err.Error(), "force=true") {
+		t.Fatalf("got %v, want the hard reset refused", err)
+	}
+	if subject := runGit(t, dir, "log", "-1", "--format=%s"); subject != "second" {
+		t.Errorf("HEAD moved to %q", subject)
+	}
+}
+
+func TestGitResetBeyondFirstCommit(t *testing.T) {
+	dir := newGitRepo(t, "only")
+	tool := &GitResetTool{Sandbox: Sandbox{Root: dir}}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"count": 1}); err == nil {
+		t.Error("expected resetting past the first commit to fail")
+	}
+}
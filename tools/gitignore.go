@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignore accumulates the rules of the .gitignore files found while walking
+// a tree. Call enter for each directory before visiting its entries; rules
+// only apply beneath the directory whose .gitignore declared them, and, as in
+// git, the last matching rule wins so later "!pattern" lines re-include.
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+type gitignoreRule struct {
+	base     string   // directory of the .gitignore, relative to the walk root ("" for the root)
+	segments []string // slash-separated pattern, "**"-prefixed when unanchored
+	negate   bool
+	dirOnly  bool
+}
+
+// enter loads dir/.gitignore, if any. relative is dir's slash-separated path
+// relative to the walk root ("." for the root itself).
+func (this *gitignore) enter(dir, relative string) {
+	if this == nil {
+		return
+	}
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer func() { _ = file.Close() }()
+	if relative == "." {
+		relative = ""
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseGitignoreLine(scanner.Text()); ok {
+			rule.base = relative
+			this.rules = append(this.rules, rule)
+		}
+	}
+}
+
+// ignored reports whether the entry at the slash-separated relative path is
+// excluded by the rules loaded so far.
+func (this *gitignore) ignored(relative string, isDir bool) bool {
+	if this == nil {
+		return false
+	}
+	ignored := false
+	for _, rule := range this.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		name := relative
+		if rule.base != "" {
+			if !strings.HasPrefix(relative, rule.base+"/") {
+				continue
+			}
+			name = strings.TrimPrefix(relative, rule.base+"/")
+		}
+		if matchSegments(rule.segments, strings.Split(name, "/")) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func parseGitignoreLine(line string) (rule gitignoreRule, ok bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+	// A slash anywhere but the end anchors the pattern to the .gitignore's
+	// directory; otherwise it matches at any depth.
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	rule.segments = strings.Split(line, "/")
+	return rule, true
+}
+
+// respectGitignore reads the optional respect_gitignore parameter, which
+// defaults to true, and returns the matcher to use (nil when disabled).
+func respectGitignore(params map[string]interface{}) *gitignore {
+	if respect, _ := GetBool(params, "respect_gitignore", true); !respect {
+		return nil
+	}
+	return &gitignore{}
+}
+
+func joinRelative(dir, name string) string {
+	if dir == "." || dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
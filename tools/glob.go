@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether a slash-separated relative path matches pattern.
+// Segments follow path.Match, and a "**" segment matches zero or more whole
+// segments, so "**/*.go" matches "main.go" and "cmd/app/main.go". A pattern
+// without a slash is matched against the last element of the path alone.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globsParam reads an optional array-of-patterns parameter, rejecting
+// malformed patterns up front rather than letting them silently never match.
+func globsParam(params map[string]interface{}, name string) ([]string, error) {
+	values, err := GetStringSlice(params, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s parameter must be an array of glob patterns", name)
+	}
+	var patterns []string
+	for _, pattern := range values {
+		if pattern == "" {
+			return nil, fmt.Errorf("%s parameter must be an array of glob patterns", name)
+		}
+		pattern = strings.TrimPrefix(pattern, "./")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %v", name, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
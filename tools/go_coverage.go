@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultCoverageTimeout = 5 * time.Minute
+
+var coverageLine = regexp.MustCompile(`^(ok|FAIL|\s*)\s*(\S+)\s.*coverage: ([0-9.]+)% of statements`)
+
+// CoverageTool runs the tests with coverage enabled and summarizes the
+// per-package and overall figures.
+type CoverageTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *CoverageTool) Name() string { return "go_coverage" }
+func (this *CoverageTool) Description() string {
+	return "Run 'go test -cover' and report statement coverage per package plus the overall figure, optionally flagging packages below a threshold"
+}
+func (this *CoverageTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"packages": map[string]interface{}{
+				"type":        "string",
+				"description": "Package pattern to test (optional, default './...')",
+			},
+			"threshold": map[string]interface{}{
+				"type":        "number",
+				"description": "Flag packages whose coverage is below this percentage (optional)",
+			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": "Maximum time to let the tests run (optional, default 300)",
+			},
+		},
+	}
+}
+func (this *CoverageTool) RequiresPermission() bool { return true }
+func (this *CoverageTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	packages, err := GetOptionalString(params, "packages", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if packages == "" {
+		packages = "./..."
+	}
+	if strings.HasPrefix(packages, "-") {
+		return ToolResult{}, fmt.Errorf("invalid packages %q", packages)
+	}
+	hasThreshold := params["threshold"] != nil
+	threshold, err := GetFloat(params, "threshold", 0)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	timeout, err := timeoutParam(params, defaultCoverageTimeout)
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	profile, err := os.CreateTemp("", "cover-*.out")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	_ = profile.Close()
+	defer func() { _ = os.Remove(profile.Name()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	args := append([]string{"test", "-cover", "-coverprofile=" + profile.Name()}, strings.Fields(packages)...)
+	output, runErr := runnerOrDefault(this.Runner)(ctx, Command{Dir: this.Root, Name: "go", Args: args})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ToolResult{}, fmt.Errorf("go test timed out after %s", timeout)
+	}
+
+	results := parseCoverageOutput(string(output))
+	if len(results) == 0 {
+		if runErr != nil {
+			return ToolResult{}, fmt.Errorf("go test failed: %v\n%s", runErr, output)
+		}
+		return ToolResult{Content: "No coverage data: no packages with Go files matched " + packages}, nil
+	}
+
+	var summary strings.Builder
+	for _, result := range results {
+		fmt.Fprintf(&summary, "%5.1f%%  %s", result.percent, result.pkg)
+		if result.failed {
+			summary.WriteString("  (tests FAILED)")
+		} else if hasThreshold && result.percent < threshold {
+			fmt.Fprintf(&summary, "  (below %.1f%%)", threshold)
+		}
+		summary.WriteString("\n")
+	}
+	if overall, ok := profileCoverage(profile.Name()); ok {
+		fmt.Fprintf(&summary, "\nOverall: %.1f%% of statements\n", overall)
+	}
+	if runErr != nil {
+		fmt.Fprintf(&summary, "\ngo test reported failures (%v); see the packages marked FAILED.\n", runErr)
+	}
+	return ToolResult{Content: summary.String()}, nil
+}
+
+type packageCoverage struct {
+	pkg     string
+	percent float64
+	failed  bool
+}
+
+// parseCoverageOutput extracts per-package percentages from go test output,
+// which look like "ok  \tpkg\t0.01s\tcoverage: 75.0% of statements" (or
+// without the status for packages that have no tests).
+func parseCoverageOutput(output string) (results []packageCoverage) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		match := coverageLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		percent, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			continue
+		}
+		results = append(results, packageCoverage{pkg: match[2], percent: percent, failed: match[1] == "FAIL"})
+	}
+	return results
+}
+
+// profileCoverage computes the statement-weighted coverage across a cover
+// profile ("file:start,end statements count" per block).
+func profileCoverage(path string) (float64, bool) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return 0, false
+	}
+	defer func() { _ = file.Close() }()
+	blocks := make(map[string][2]int) // block -> statements, count
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "mode:") {
+			continue
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		previous := blocks[fields[0]]
+		blocks[fields[0]] = [2]int{statements, max(previous[1], count)}
+	}
+	var total, covered int
+	for _, block := range blocks {
+		total += block[0]
+		if block[1] > 0 {
+			covered += block[0]
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(covered) / float64(total), true
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCoverageOfFixtureModule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go isn't installed")
+	}
+	tool := &CoverageTool{Sandbox: Sandbox{Root: "testdata/coverage"}}
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"threshold": 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		" 50.0%  example.com/coverage/calc  (below 60.0%)\n",
+		"  0.0%  example.com/coverage/untested  (below 60.0%)\n",
+		"Overall: 33.3% of statements\n",
+	} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("%q missing from\n%s", want, result.Content)
+		}
+	}
+}
+
+func TestParseCoverageOutput(t *testing.T) {
+	output := "ok  \texample.com/a\t0.01s\tcoverage: 75.0% of statements\n" +
+		"--- FAIL: TestB (0.00s)\n" +
+		"FAIL\texample.com/b\t0.02s\tcoverage: 12.5% of statements\n" +
+		"\texample.com/c\t\tcoverage: 0.0% of statements\n" +
+		"ok  \texample.com/d\t0.01s\t[no tests to run]\n"
+	want := []packageCoverage{
+		{pkg: "example.com/a", percent: 75},
+		{pkg: "example.com/b", percent: 12.5, failed: true},
+		{pkg: "example.com/c", percent: 0},
+	}
+	got := parseCoverageOutput(output)
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v, want %+v", got[i], want[i])
+		}
+	}
+}
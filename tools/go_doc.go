@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxGoDocBytes = 32 * 1024
+
+var (
+	goPackagePath = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./~-]*(@[A-Za-z0-9._+-]+)?$`)
+	goSymbol      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// GoDocTool returns package and symbol documentation from `go doc`, so the
+// model can consult an API instead of guessing at it.
+type GoDocTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *GoDocTool) Name() string { return "go_doc" }
+func (this *GoDocTool) Description() string {
+	return "Show Go documentation via 'go doc' for a package (standard library or a module dependency) and optionally one of its symbols, e.g. package 'net/http' with symbol 'Client.Do'"
+}
+func (this *GoDocTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"package": map[string]interface{}{
+				"type":        "string",
+				"description": "Import path of the package, e.g. 'strings' or 'github.com/user/repo/pkg'",
+			},
+			"symbol": map[string]interface{}{
+				"type":        "string",
+				"description": "Symbol to document, e.g. 'Builder' or 'Builder.WriteString' (optional)",
+			},
+			"all": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Show documentation for every exported symbol in the package (optional, default false)",
+			},
+		},
+		"required": []string{"package"},
+	}
+}
+func (this *GoDocTool) RequiresPermission() bool { return false }
+func (this *GoDocTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	pkg, err := GetString(params, "package")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if !goPackagePath.MatchString(pkg) {
+		return ToolResult{}, errors.New("package parameter must be a Go import path")
+	}
+	args := []string{"doc"}
+	all, err := GetBool(params, "all", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if all {
+		args = append(args, "-all")
+	}
+	target := pkg
+	symbol, err := GetOptionalString(params, "symbol", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if symbol != "" {
+		if !goSymbol.MatchString(symbol) {
+			return ToolResult{}, fmt.Errorf("invalid symbol %q: expected Name or Type.Method", symbol)
+		}
+		target += "." + symbol
+	}
+	args = append(args, target)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	output, err := runnerOrDefault(this.Runner)(ctx, Command{Dir: this.Root, Name: "go", Args: args})
+	text := strings.TrimSpace(string(output))
+	if err != nil {
+		if text == "" {
+			return ToolResult{}, fmt.Errorf("go doc %s failed: %v", target, err)
+		}
+		return ToolResult{}, fmt.Errorf("go doc %s failed: %s", target, strings.TrimPrefix(text, "doc: "))
+	}
+	if text == "" {
+		return ToolResult{}, fmt.Errorf("go doc %s produced no documentation", target)
+	}
+	if len(text) > maxGoDocBytes {
+		text = text[:maxGoDocBytes] + fmt.Sprintf("\n[truncated: documentation exceeds %d bytes; ask for a specific symbol]", maxGoDocBytes)
+	}
+	return ToolResult{Content: text}, nil
+}
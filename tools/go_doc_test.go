@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const cannedGoDoc = `package strings // import "strings"
+
+func Cut(s, sep string) (before, after string, found bool)
+    Cut slices s around the first instance of sep.
+`
+
+func TestGoDocPassesOutputThrough(t *testing.T) {
+	var ran Command
+	tool := &GoDocTool{Sandbox: Sandbox{Root: "/project"}, Runner: func(ctx context.Context, command Command) ([]byte, error) {
+		ran = command
+		return []byte(cannedGoDoc), nil
+	}}
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"package": "strings", "symbol": "Cut"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != strings.TrimSpace(cannedGoDoc) {
+		t.Errorf("got %q", result.Content)
+	}
+	if ran.Dir != "/project" || ran.Name != "go" || !slices.Equal(ran.Args, []string{"doc", "strings.Cut"}) {
+		t.Errorf("ran %+v", ran)
+	}
+}
+
+func TestGoDocErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		params map[string]interface{}
+		output string
+		want   string
+	}{
+		{name: "unknown package", params: map[string]interface{}{"package": "nosuchpkg"}, output: "doc: no such package nosuchpkg\n", want: "go doc nosuchpkg failed: no such package nosuchpkg"},
+		{name: "unknown symbol", params: map[string]interface{}{"package": "strings", "symbol": "Nope"}, output: "doc: no symbol Nope in package strings\n", want: "no symbol Nope in package strings"},
+		{name: "option as package", params: map[string]interface{}{"package": "-u"}, want: "must be a Go import path"},
+		{name: "invalid symbol", params: map[string]interface{}{"package": "strings", "symbol": "Cut;ls"}, want: "invalid symbol"},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tool := &GoDocTool{Runner: func(ctx context.Context, command Command) ([]byte, error) {
+				return []byte(test.output), errors.New("exit status 1")
+			}}
+			_, err := tool.Execute(context.Background(), test.params)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// GoSymbolTool returns the source of one top-level declaration in a Go file,
+// so the model can read a function without reading the whole file.
+type GoSymbolTool struct {
+	Sandbox
+}
+
+func (this *GoSymbolTool) Name() string { return "go_symbol" }
+func (this *GoSymbolTool) Description() string {
+	return "Show the source of one function, method, type, variable or constant in a Go file, with its doc comment, instead of reading the whole file. " +
+		"If the symbol isn't declared there, the file's top-level declarations are listed."
+}
+func (this *GoSymbolTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the .go file",
+			},
+			"symbol": map[string]interface{}{
+				"type":        "string",
+				"description": "The name of a function, type, variable or constant, or Type.Method for a method, e.g. 'NewServer' or 'Server.Start'",
+			},
+		},
+		"required": []string{"path", "symbol"},
+	}
+}
+func (this *GoSymbolTool) RequiresPermission() bool { return false }
+func (this *GoSymbolTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	sourcePath, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	symbol, err := GetNonEmptyString(params, "symbol")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	symbol = strings.TrimPrefix(strings.TrimSpace(symbol), "*")
+	sourcePath, err = this.Resolve(sourcePath)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if !strings.HasSuffix(sourcePath, ".go") {
+		return ToolResult{}, fmt.Errorf("%s is not a Go source file", sourcePath)
+	}
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, sourcePath, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	start, end, keyword, found := findGoSymbol(file, symbol)
+	if !found {
+		return ToolResult{}, fmt.Errorf("%s is not declared at the top level of %s; it declares:\n%s", symbol, sourcePath, strings.Join(topLevelNames(file), "\n"))
+	}
+	from, to := fileSet.Position(start), fileSet.Position(end)
+	return ToolResult{
+		Content:  fmt.Sprintf("// %s:%d-%d\n%s%s\n", sourcePath, from.Line, to.Line, keyword, content[from.Offset:to.Offset]),
+		Metadata: map[string]interface{}{MetaPath: sourcePath},
+	}, nil
+}
+
+// findGoSymbol locates the declaration of symbol (a name, or Type.Method),
+// including its doc comment. A type, variable or constant declared in a
+// parenthesized group yields just its own spec, along with the keyword
+// ("const ", say) to put in front of it.
+func findGoSymbol(file *ast.File, symbol string) (start, end token.Pos, keyword string, found bool) {
+	if fn := findFuncDecl(file, symbol); fn != nil {
+		return withDoc(fn.Doc, fn.Pos()), fn.End(), "", true
+	}
+	for _, declaration := range file.Decls {
+		general, ok := declaration.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range general.Specs {
+			var names []*ast.Ident
+			var doc *ast.CommentGroup
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names, doc = []*ast.Ident{spec.Name}, spec.Doc
+			case *ast.ValueSpec:
+				names, doc = spec.Names, spec.Doc
+			}
+			for _, name := range names {
+				if name.Name != symbol {
+					continue
+				}
+				if !general.Lparen.IsValid() {
+					return withDoc(general.Doc, general.Pos()), general.End(), "", true
+				}
+				if doc != nil {
+					return doc.Pos(), spec.End(), "", true // the keyword would land before the comment
+				}
+				return spec.Pos(), spec.End(), general.Tok.String() + " ", true
+			}
+		}
+	}
+	return token.NoPos, token.NoPos, "", false
+}
+
+func withDoc(doc *ast.CommentGroup, position token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return position
+}
+
+// topLevelNames lists a file's declarations, e.g. "func (Server) Start" or
+// "type Server", in source order.
+func topLevelNames(file *ast.File) (names []string) {
+	for _, declaration := range file.Decls {
+		switch declaration := declaration.(type) {
+		case *ast.FuncDecl:
+			if declaration.Recv != nil && len(declaration.Recv.List) > 0 {
+				names = append(names, fmt.Sprintf("func (%s) %s", receiverTypeName(declaration.Recv.List[0].Type), declaration.Name.Name))
+			} else {
+				names = append(names, "func "+declaration.Name.Name)
+			}
+		case *ast.GenDecl:
+			if declaration.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range declaration.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, "type "+spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.Name != "_" {
+							names = append(names, declaration.Tok.String()+" "+name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
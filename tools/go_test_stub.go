@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateTestStubTool emits a table-driven test skeleton for a Go function or method.
+type GenerateTestStubTool struct {
+	Sandbox
+	Session
+}
+
+func (this *GenerateTestStubTool) Name() string { return "go_test_stub" }
+func (this *GenerateTestStubTool) Description() string {
+	return "Generate a table-driven TestXxx skeleton for a Go function or method (name or Type.Method), with input and expected-output fields derived from its signature. " +
+		"Returns the stub for review, or appends it to the corresponding _test.go file when write is true. Generic functions are not supported."
+}
+func (this *GenerateTestStubTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the Go source file declaring the function",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Function name, or Type.Method for a method",
+			},
+			"write": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Append the stub to the file's _test.go counterpart instead of returning it (optional, default false)",
+			},
+		},
+		"required": []string{"path", "name"},
+	}
+}
+func (this *GenerateTestStubTool) RequiresPermission() bool { return true }
+func (this *GenerateTestStubTool) RequiresPermissionFor(params map[string]interface{}) bool {
+	write, _ := GetBool(params, "write", false)
+	return write
+}
+func (this *GenerateTestStubTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	sourcePath, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	name, err := GetNonEmptyString(params, "name")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	write, err := GetBool(params, "write", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	sourcePath, err = this.Resolve(sourcePath)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if strings.HasSuffix(sourcePath, "_test.go") || !strings.HasSuffix(sourcePath, ".go") {
+		return ToolResult{}, fmt.Errorf("%s is not a non-test Go source file", sourcePath)
+	}
+
+	fileSet := token.NewFileSet()
+	source, err := parser.ParseFile(fileSet, sourcePath, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	declaration := findFuncDecl(source, name)
+	if declaration == nil {
+		return ToolResult{}, fmt.Errorf("function %s not found in %s", name, sourcePath)
+	}
+	if declaration.Type.TypeParams != nil {
+		return ToolResult{}, fmt.Errorf("%s is generic; generic functions are not supported", name)
+	}
+	stub := generateTestStub(declaration)
+	imports := stubImports(source, declaration, stub)
+	if !write {
+		return ToolResult{Content: fmt.Sprintf("// imports: %s\n\n%s", strings.Join(imports, ", "), stub)}, nil
+	}
+
+	testPath := strings.TrimSuffix(sourcePath, ".go") + "_test.go"
+	content, err := appendTestStub(testPath, source.Name.Name, testFuncName(declaration), imports, stub)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	before, readErr := os.ReadFile(testPath)
+	if err := os.WriteFile(testPath, content, 0644); err != nil {
+		return ToolResult{}, err
+	}
+	this.recordWrite(testPath, string(content))
+	this.recordEdit(testPath, before, readErr)
+	this.markRead(testPath)
+	return ToolResult{
+		Content:  fmt.Sprintf("Added %s to %s", testFuncName(declaration), testPath),
+		Metadata: map[string]interface{}{MetaPath: testPath, MetaBytesWritten: len(content)},
+	}, nil
+}
+
+func findFuncDecl(file *ast.File, name string) *ast.FuncDecl {
+	receiver, method, isMethod := strings.Cut(name, ".")
+	for _, declaration := range file.Decls {
+		fn, ok := declaration.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if !isMethod && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+		if isMethod && fn.Recv != nil && len(fn.Recv.List) > 0 &&
+			fn.Name.Name == method && receiverTypeName(fn.Recv.List[0].Type) == receiver {
+			return fn
+		}
+	}
+	return nil
+}
+
+func testFuncName(fn *ast.FuncDecl) string {
+	name := fn.Name.Name
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		name = receiverTypeName(fn.Recv.List[0].Type) + "_" + name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return "Test" + string(runes)
+}
+
+type stubField struct{ name, typ string }
+
+func generateTestStub(fn *ast.FuncDecl) string {
+	var fields, arguments []string
+	var inputs, wants []stubField
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		inputs = append(inputs, stubField{"receiver", types.ExprString(fn.Recv.List[0].Type)})
+	}
+	for i, param := range fn.Type.Params.List {
+		typ := types.ExprString(param.Type)
+		variadic := false
+		if ellipsis, ok := param.Type.(*ast.Ellipsis); ok {
+			typ, variadic = "[]"+types.ExprString(ellipsis.Elt), true
+		}
+		names := param.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "arg" + strconv.Itoa(i)}}
+		}
+		for _, name := range names {
+			field := name.Name
+			if field == "_" {
+				field = "arg" + strconv.Itoa(len(arguments))
+			}
+			inputs = append(inputs, stubField{field, typ})
+			argument := "test." + field
+			if variadic {
+				argument += "..."
+			}
+			arguments = append(arguments, argument)
+		}
+	}
+
+	var results []string
+	returnsError := false
+	if fn.Type.Results != nil {
+		var resultTypes []string
+		for _, result := range fn.Type.Results.List {
+			for range max(1, len(result.Names)) {
+				resultTypes = append(resultTypes, types.ExprString(result.Type))
+			}
+		}
+		for i, typ := range resultTypes {
+			if i == len(resultTypes)-1 && typ == "error" {
+				returnsError = true
+				results = append(results, "err")
+				continue
+			}
+			suffix := ""
+			if len(wants) > 0 {
+				suffix = strconv.Itoa(len(wants))
+			}
+			wants = append(wants, stubField{"want" + suffix, typ})
+			results = append(results, "got"+suffix)
+		}
+	}
+
+	fields = append(fields, "name string")
+	for _, field := range slices.Concat(inputs, wants) {
+		fields = append(fields, field.name+" "+field.typ)
+	}
+	if returnsError {
+		fields = append(fields, "wantErr bool")
+	}
+
+	call := fn.Name.Name + "(" + strings.Join(arguments, ", ") + ")"
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		call = "test.receiver." + call
+	}
+	label := fn.Name.Name + "()"
+
+	var stub strings.Builder
+	fmt.Fprintf(&stub, "func %s(t *testing.T) {\n", testFuncName(fn))
+	fmt.Fprintf(&stub, "\ttests := []struct {\n\t\t%s\n\t}{\n\t\t// TODO: add test cases.\n\t}\n", strings.Join(fields, "\n\t\t"))
+	stub.WriteString("\tfor _, test := range tests {\n\t\tt.Run(test.name, func(t *testing.T) {\n")
+	if len(results) > 0 {
+		fmt.Fprintf(&stub, "\t\t\t%s := %s\n", strings.Join(results, ", "), call)
+	} else {
+		fmt.Fprintf(&stub, "\t\t\t%s\n", call)
+	}
+	if returnsError {
+		fmt.Fprintf(&stub, "\t\t\tif (err != nil) != test.wantErr {\n\t\t\t\tt.Fatalf(\"%s error = %%v, wantErr %%v\", err, test.wantErr)\n\t\t\t}\n", label)
+	}
+	for _, want := range wants {
+		got := strings.Replace(want.name, "want", "got", 1)
+		fmt.Fprintf(&stub, "\t\t\tif !reflect.DeepEqual(%s, test.%s) {\n\t\t\t\tt.Errorf(\"%s %s = %%v, want %%v\", %s, test.%s)\n\t\t\t}\n", got, want.name, label, got, got, want.name)
+	}
+	stub.WriteString("\t\t})\n\t}\n}\n")
+
+	formatted, err := format.Source([]byte(stub.String()))
+	if err != nil {
+		return stub.String()
+	}
+	return string(formatted)
+}
+
+// stubImports lists the import paths the stub needs: testing, reflect when
+// results are compared, and any packages the signature's types refer to.
+func stubImports(source *ast.File, fn *ast.FuncDecl, stub string) []string {
+	imports := []string{"testing"}
+	if strings.Contains(stub, "reflect.DeepEqual") {
+		imports = append(imports, "reflect")
+	}
+	ast.Inspect(fn.Type, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if qualifier, ok := selector.X.(*ast.Ident); ok {
+			if importPath := importPathFor(source, qualifier.Name); importPath != "" && !slices.Contains(imports, importPath) {
+				imports = append(imports, importPath)
+			}
+		}
+		return false
+	})
+	return imports
+}
+
+func importPathFor(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if (spec.Name != nil && spec.Name.Name == name) || (spec.Name == nil && path.Base(importPath) == name) {
+			return importPath
+		}
+	}
+	return ""
+}
+
+// appendTestStub returns the test file's content with the stub appended and any
+// missing imports added, creating the file content if needed.
+func appendTestStub(testPath, packageName, testName string, imports []string, stub string) ([]byte, error) {
+	existing, err := os.ReadFile(testPath)
+	if errors.Is(err, os.ErrNotExist) {
+		existing = []byte("package " + packageName + "\n")
+	} else if err != nil {
+		return nil, err
+	}
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, testPath, existing, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	if full, err := parser.ParseFile(token.NewFileSet(), testPath, existing, parser.SkipObjectResolution); err == nil {
+		for _, declaration := range full.Decls {
+			if fn, ok := declaration.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == testName {
+				return nil, fmt.Errorf("%s already declares %s", testPath, testName)
+			}
+		}
+	}
+
+	var missing []string
+	for _, importPath := range imports {
+		found := false
+		for _, spec := range file.Imports {
+			if unquoted, _ := strconv.Unquote(spec.Path.Value); unquoted == importPath {
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, strconv.Quote(importPath))
+		}
+	}
+
+	content := existing
+	if len(missing) > 0 {
+		insertion := "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)\n"
+		offset := fileSet.Position(file.Name.End()).Offset
+		if len(file.Decls) > 0 {
+			if imports, ok := file.Decls[0].(*ast.GenDecl); ok && imports.Lparen.IsValid() {
+				insertion = "\n\t" + strings.Join(missing, "\n\t")
+				offset = fileSet.Position(imports.Lparen).Offset + 1
+			}
+		}
+		content = slices.Concat(existing[:offset], []byte(insertion), existing[offset:])
+	}
+	content = append(bytes.TrimRight(content, "\n"), "\n\n"+stub...)
+	formatted, err := format.Source(content)
+	if err != nil {
+		return nil, fmt.Errorf("generated test file does not parse: %v", err)
+	}
+	return formatted, nil
+}
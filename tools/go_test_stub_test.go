@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const stubSource = `package calc
+
+import "time"
+
+func Scale(value int, factor time.Duration) (time.Duration, error) { return 0, nil }
+`
+
+func TestGoTestStubForTwoArgumentFunction(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"calc.go": stubSource})
+	tool := &GenerateTestStubTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"path": "calc.go", "name": "Scale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func TestScale(t *testing.T) {",
+		"tests := []struct {",
+		"value   int\n",
+		"factor  time.Duration\n",
+		"want    time.Duration\n",
+		"wantErr bool\n",
+		"got, err := Scale(test.value, test.factor)",
+	} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("%q missing from the stub:\n%s", want, result.Content)
+		}
+	}
+	if readTestFile(t, filepath.Join(dir, "calc.go")) != stubSource {
+		t.Error("returning the stub changed the source")
+	}
+}
+
+func TestGoTestStubWritesTestFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"calc.go": stubSource})
+	tool := &GenerateTestStubTool{Sandbox: Sandbox{Root: dir}}
+	params := map[string]interface{}{"path": "calc.go", "name": "Scale", "write": true}
+	if !tool.RequiresPermissionFor(params) {
+		t.Error("writing the stub should need permission")
+	}
+	if _, err := tool.Execute(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "calc_test.go"), nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("the test file doesn't parse: %v", err)
+	}
+	var imports []string
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imports = append(imports, path)
+	}
+	slices.Sort(imports)
+	if file.Name.Name != "calc" || !slices.Equal(imports, []string{"reflect", "testing", "time"}) {
+		t.Errorf("package %s imports %v", file.Name.Name, imports)
+	}
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultGrepMatches = 200
+	maxGrepFileBytes   = 1024 * 1024
+	maxGrepLineLength  = 300
+)
+
+// GrepTool searches the contents of the text files under a directory for a
+// regular expression.
+type GrepTool struct {
+	Sandbox
+}
+
+func (this *GrepTool) Name() string { return "grep" }
+func (this *GrepTool) Description() string {
+	return "Search the text files under a directory for a regular expression (Go RE2 syntax) and return the matching lines as path:line: text. " +
+		"Use it to find where something is defined or used instead of reading whole directories. " +
+		"Dot-directories (such as .git), binary files and files over 1MB are skipped, as are entries matched by .gitignore files unless respect_gitignore is false."
+}
+func (this *GrepTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "The regular expression to search for, e.g. 'func New\\w*' or 'TODO'",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory (or file) to search (optional, default '.')",
+			},
+			"include": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only search files whose path relative to the directory matches one of these globs (optional), e.g. [\"**/*.go\"]",
+			},
+			"ignore_case": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Match without regard to case (optional, default false)",
+			},
+			"max_matches": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Show at most this many matching lines (optional, default %d)", defaultGrepMatches),
+			},
+			"respect_gitignore": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Skip files and directories matched by .gitignore files found in the tree (optional, default true)",
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+func (this *GrepTool) RequiresPermission() bool { return false }
+func (this *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	pattern, err := GetString(params, "pattern")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if pattern == "" {
+		return ToolResult{}, fmt.Errorf("pattern parameter must be a non-empty regular expression")
+	}
+	ignoreCase, err := GetBool(params, "ignore_case", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("invalid pattern: %v", err)
+	}
+	root, err := GetOptionalString(params, "path", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	include, err := globsParam(params, "include")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	maxMatches, err := GetInt(params, "max_matches", defaultGrepMatches)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if maxMatches <= 0 {
+		maxMatches = defaultGrepMatches
+	}
+	ignore := respectGitignore(params)
+
+	results := newCappedResults(maxMatches)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			results.Skip(path) // e.g. a directory without read permission
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(root, path)
+		relative = filepath.ToSlash(relative)
+		if entry.IsDir() {
+			if relative != "." && (strings.HasPrefix(entry.Name(), ".") || ignore.ignored(relative, true)) {
+				return filepath.SkipDir
+			}
+			ignore.enter(path, relative)
+			return nil
+		}
+		if relative != "." && (ignore.ignored(relative, false) || (len(include) > 0 && !matchAnyGlob(include, relative))) {
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if _, err := this.Resolve(path); err != nil {
+				return nil // a link pointing outside the sandbox
+			}
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() || info.Size() > maxGrepFileBytes {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			results.Skip(path)
+			return nil
+		}
+		if !utf8.Valid(content) {
+			return nil // binary
+		}
+		for number, line := range strings.Split(string(content), "\n") {
+			if expression.MatchString(line) {
+				results.Add(fmt.Sprintf("%s:%d: %s", path, number+1, truncateLine(strings.TrimRight(line, "\r"))))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: results.String("matches"), Metadata: map[string]interface{}{MetaTruncated: results.Truncated()}}, nil
+}
+
+// truncateLine shortens a matching line (e.g. from minified code) so a single
+// match can't flood the output.
+func truncateLine(line string) string {
+	if len(line) <= maxGrepLineLength {
+		return line
+	}
+	line = line[:maxGrepLineLength]
+	for len(line) > 0 && !utf8.ValidString(line) {
+		line = line[:len(line)-1] // don't split a multi-byte character
+	}
+	return line + "…"
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// unreadableDir makes dir/name a directory that can't be listed, skipping the
+// test where permissions aren't enforced.
+func unreadableDir(t *testing.T, dir, name string) string {
+	t.Helper()
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(path, 0755) })
+	return path
+}
+
+func TestGrep(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":          "package main\n\nfunc handler() {}\n",
+		"sub/util.go":      "package sub\n\n// handler helpers\n",
+		"notes.txt":        "handler\n",
+		".git/config":      "handler\n",
+		"binary.bin":       "handler\x00\xff\n",
+		"sub/deeper/x.go":  "package deeper\n",
+		"locked/secret.go": "func handler() {}\n",
+	})
+	tool := &GrepTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"pattern": `\bhandler\b`, "include": "*.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		filepath.Join(dir, "main.go") + ":3: func handler() {}",
+		filepath.Join(dir, "sub/util.go") + ":3: // handler helpers",
+		filepath.Join(dir, "locked/secret.go") + ":1: func handler() {}",
+		"[3 matches]",
+	} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("no %q in:\n%s", want, result.Content)
+		}
+	}
+	if strings.Contains(result.Content, "notes.txt") || strings.Contains(result.Content, ".git") {
+		t.Errorf("excluded files matched:\n%s", result.Content)
+	}
+}
+
+func TestGrepSkipsUnreadableDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":          "func handler() {}\n",
+		"locked/secret.go": "func handler() {}\n",
+	})
+	locked := unreadableDir(t, dir, "locked")
+	tool := &GrepTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"pattern": "handler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, filepath.Join(dir, "main.go")+":1:") {
+		t.Errorf("readable match missing:\n%s", result.Content)
+	}
+	if !strings.HasSuffix(result.Content, "[skipped 1 unreadable entries: "+locked+"]\n") {
+		t.Errorf("the skipped directory isn't mentioned:\n%s", result.Content)
+	}
+}
@@ -0,0 +1,390 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxLintConfigBytes = 1024 * 1024
+
+// LintConfigTool performs structural validation of Dockerfiles and YAML
+// configuration (docker-compose, Kubernetes manifests, GitHub Actions
+// workflows) without relying on external linters.
+type LintConfigTool struct {
+	Sandbox
+}
+
+func (this *LintConfigTool) Name() string { return "lint_config" }
+func (this *LintConfigTool) Description() string {
+	return "Validate a Dockerfile or YAML config (docker-compose, Kubernetes manifest, GitHub Actions workflow, or plain YAML) and list findings with line numbers: " +
+		"YAML structure errors (tabs, bad indentation, duplicate keys, unterminated quotes), missing required fields, and common anti-patterns. " +
+		"The file type is detected from the name and content unless given."
+}
+func (this *LintConfigTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to lint",
+			},
+			"type": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"dockerfile", "compose", "kubernetes", "github-actions", "yaml"},
+				"description": "File type (optional, detected by default)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+func (this *LintConfigTool) RequiresPermission() bool { return false }
+func (this *LintConfigTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if info.Size() > maxLintConfigBytes {
+		return ToolResult{}, fmt.Errorf("%s is too large to lint (%d bytes)", path, info.Size())
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	content := string(raw)
+
+	kind, err := GetOptionalString(params, "type", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if kind == "" && detectConfigKind(path, nil) == "dockerfile" {
+		kind = "dockerfile"
+	}
+	var findings []lintFinding
+	if kind == "dockerfile" {
+		findings = lintDockerfile(content)
+	} else {
+		var documents []*yamlNode
+		documents, findings = parseYAMLOutline(content)
+		if kind == "" {
+			kind = detectConfigKind(path, documents)
+		}
+		for _, document := range documents {
+			switch kind {
+			case "compose":
+				findings = append(findings, lintCompose(document)...)
+			case "kubernetes":
+				findings = append(findings, lintKubernetes(document)...)
+			case "github-actions":
+				findings = append(findings, lintWorkflow(document)...)
+			case "yaml":
+			default:
+				return ToolResult{}, fmt.Errorf("unknown type %q", kind)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].line < findings[j].line })
+	if len(findings) == 0 {
+		return ToolResult{Content: fmt.Sprintf("%s (%s): no problems found", path, kind)}, nil
+	}
+	var report strings.Builder
+	fmt.Fprintf(&report, "%s (%s): %d finding(s)\n", path, kind, len(findings))
+	for _, finding := range findings {
+		fmt.Fprintf(&report, "%s:%s\n", path, finding)
+	}
+	return ToolResult{Content: report.String()}, nil
+}
+
+// detectConfigKind guesses the file type from its name and, for YAML, its top-level keys.
+func detectConfigKind(path string, documents []*yamlNode) string {
+	base := strings.ToLower(filepath.Base(path))
+	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+		return "dockerfile"
+	}
+	if strings.Contains(filepath.ToSlash(path), ".github/workflows/") {
+		return "github-actions"
+	}
+	if strings.HasPrefix(base, "docker-compose") || strings.HasPrefix(base, "compose.") {
+		return "compose"
+	}
+	for _, document := range documents {
+		switch {
+		case document.child("apiVersion") != nil || document.child("kind") != nil:
+			return "kubernetes"
+		case document.child("jobs") != nil && (document.child("on") != nil || document.child("true") != nil):
+			return "github-actions"
+		case document.child("services") != nil:
+			return "compose"
+		}
+	}
+	return "yaml"
+}
+
+func lintRequired(findings *[]lintFinding, parent *yamlNode, field, context string) *yamlNode {
+	node := parent.path(field)
+	if node == nil {
+		*findings = append(*findings, lintFinding{line: max(parent.line, 1), severity: "error", message: fmt.Sprintf("%s is missing required field %q", context, field)})
+	}
+	return node
+}
+
+func lintWarning(findings *[]lintFinding, line int, format string, args ...interface{}) {
+	*findings = append(*findings, lintFinding{line: line, severity: "warning", message: fmt.Sprintf(format, args...)})
+}
+
+// imageTagProblem describes what is risky about an image reference, or returns "".
+func imageTagProblem(image string) string {
+	image = strings.Trim(image, `"'`)
+	if image == "" || strings.Contains(image, "$") || strings.Contains(image, "@sha256:") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	switch {
+	case !strings.Contains(name, ":"):
+		return fmt.Sprintf("image %q has no tag (implicitly :latest); pin a version", image)
+	case strings.HasSuffix(name, ":latest"):
+		return fmt.Sprintf("image %q uses the :latest tag; pin a version", image)
+	}
+	return ""
+}
+
+func lintCompose(document *yamlNode) (findings []lintFinding) {
+	if len(document.children) == 0 {
+		return nil
+	}
+	services := lintRequired(&findings, document, "services", "compose file")
+	if services == nil {
+		return findings
+	}
+	for _, service := range services.children {
+		context := fmt.Sprintf("service %q", service.key)
+		image, build := service.child("image"), service.child("build")
+		if image == nil && build == nil {
+			findings = append(findings, lintFinding{line: service.line, severity: "error", message: context + " needs either image or build"})
+		}
+		if image != nil {
+			if problem := imageTagProblem(image.value); problem != "" {
+				lintWarning(&findings, image.line, "%s", problem)
+			}
+		}
+		if privileged := service.child("privileged"); privileged != nil && privileged.value == "true" {
+			lintWarning(&findings, privileged.line, "%s runs privileged", context)
+		}
+		for _, key := range []string{"ports", "volumes", "environment"} {
+			if node := service.child(key); node != nil && node.value != "" && !strings.HasPrefix(node.value, "[") && !strings.HasPrefix(node.value, "{") {
+				findings = append(findings, lintFinding{line: node.line, severity: "error", message: fmt.Sprintf("%s: %s must be a list or mapping, not a scalar", context, key)})
+			}
+		}
+	}
+	return findings
+}
+
+var kubernetesWorkloads = map[string]bool{
+	"Deployment": true, "StatefulSet": true, "DaemonSet": true, "ReplicaSet": true, "Job": true,
+}
+
+func lintKubernetes(document *yamlNode) (findings []lintFinding) {
+	if len(document.children) == 0 {
+		return nil
+	}
+	lintRequired(&findings, document, "apiVersion", "manifest")
+	kind := lintRequired(&findings, document, "kind", "manifest")
+	lintRequired(&findings, document, "metadata.name", "manifest")
+	if kind == nil {
+		return findings
+	}
+	context := kind.value
+	podSpec := document.path("spec")
+	switch {
+	case kubernetesWorkloads[kind.value]:
+		lintRequired(&findings, document, "spec.template", context)
+		if kind.value != "Job" {
+			lintRequired(&findings, document, "spec.selector", context)
+		}
+		podSpec = document.path("spec.template.spec")
+	case kind.value == "CronJob":
+		podSpec = document.path("spec.jobTemplate.spec.template.spec")
+	case kind.value != "Pod":
+		return findings
+	}
+	if podSpec == nil {
+		return findings
+	}
+	containers := lintRequired(&findings, podSpec, "containers", context+" pod spec")
+	if containers == nil {
+		return findings
+	}
+	for _, container := range containers.items {
+		name := container.child("name")
+		label := fmt.Sprintf("container on line %d", container.line)
+		if name != nil {
+			label = fmt.Sprintf("container %q", name.value)
+		} else {
+			lintRequired(&findings, container, "name", label)
+		}
+		image := lintRequired(&findings, container, "image", label)
+		if image != nil {
+			if problem := imageTagProblem(image.value); problem != "" {
+				lintWarning(&findings, image.line, "%s", problem)
+			}
+		}
+		if container.child("resources") == nil {
+			lintWarning(&findings, container.line, "%s has no resource requests/limits", label)
+		}
+		if privileged := container.path("securityContext.privileged"); privileged != nil && privileged.value == "true" {
+			lintWarning(&findings, privileged.line, "%s runs privileged", label)
+		}
+	}
+	return findings
+}
+
+func lintWorkflow(document *yamlNode) (findings []lintFinding) {
+	if len(document.children) == 0 {
+		return nil
+	}
+	// YAML 1.1 parsers read a bare `on` key as the boolean true, but GitHub accepts it.
+	if document.child("on") == nil && document.child("true") == nil {
+		lintRequired(&findings, document, "on", "workflow")
+	}
+	jobs := lintRequired(&findings, document, "jobs", "workflow")
+	if jobs == nil {
+		return findings
+	}
+	for _, job := range jobs.children {
+		context := fmt.Sprintf("job %q", job.key)
+		if job.child("uses") != nil {
+			continue // reusable workflow call
+		}
+		lintRequired(&findings, job, "runs-on", context)
+		steps := lintRequired(&findings, job, "steps", context)
+		if steps == nil {
+			continue
+		}
+		for _, step := range steps.items {
+			run, uses := step.child("run"), step.child("uses")
+			if run == nil && uses == nil {
+				findings = append(findings, lintFinding{line: step.line, severity: "error", message: context + ": step needs either run or uses"})
+			}
+			if run != nil && uses != nil {
+				findings = append(findings, lintFinding{line: step.line, severity: "error", message: context + ": step cannot have both run and uses"})
+			}
+			if uses != nil && !strings.HasPrefix(uses.value, "./") && !strings.HasPrefix(uses.value, "docker://") && !strings.Contains(uses.value, "@") {
+				lintWarning(&findings, uses.line, "%s: action %q is not pinned to a version (@ref)", context, uses.value)
+			}
+		}
+	}
+	return findings
+}
+
+var (
+	dockerInstruction  = regexp.MustCompile(`^([A-Za-z]+)(?:\s+(.*))?$`)
+	dockerInstructions = map[string]bool{
+		"FROM": true, "RUN": true, "CMD": true, "LABEL": true, "MAINTAINER": true, "EXPOSE": true, "ENV": true,
+		"ADD": true, "COPY": true, "ENTRYPOINT": true, "VOLUME": true, "USER": true, "WORKDIR": true, "ARG": true,
+		"ONBUILD": true, "STOPSIGNAL": true, "HEALTHCHECK": true, "SHELL": true,
+	}
+	aptInstall  = regexp.MustCompile(`\bapt(-get)? install\b`)
+	cdInRun     = regexp.MustCompile(`(^|&&|;)\s*cd\s`)
+	remoteOrTar = regexp.MustCompile(`https?://|\.(tar|tgz|tar\.gz|tar\.bz2|tar\.xz)(\s|$)`)
+)
+
+func lintDockerfile(content string) (findings []lintFinding) {
+	var sawFrom, reportedOrder bool
+	var lastCmd, lastEntrypoint, lastUser int
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		instruction := strings.TrimSpace(lines[i])
+		for strings.HasSuffix(instruction, "\\") && i+1 < len(lines) {
+			i++
+			next := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(next, "#") {
+				continue
+			}
+			instruction = strings.TrimSuffix(instruction, "\\") + " " + next
+		}
+		if instruction == "" || strings.HasPrefix(instruction, "#") {
+			continue
+		}
+		match := dockerInstruction.FindStringSubmatch(instruction)
+		if match == nil {
+			findings = append(findings, lintFinding{line: number, severity: "error", message: fmt.Sprintf("cannot parse instruction %q", instruction)})
+			continue
+		}
+		keyword, arguments := strings.ToUpper(match[1]), strings.TrimSpace(match[2])
+		if !dockerInstructions[keyword] {
+			findings = append(findings, lintFinding{line: number, severity: "error", message: fmt.Sprintf("unknown instruction %s", match[1])})
+			continue
+		}
+		if !sawFrom && !reportedOrder && keyword != "FROM" && keyword != "ARG" {
+			findings = append(findings, lintFinding{line: number, severity: "error", message: fmt.Sprintf("%s before FROM: a Dockerfile must start with FROM (only ARG may precede it)", keyword)})
+			reportedOrder = true
+		}
+		switch keyword {
+		case "FROM":
+			sawFrom = true
+			fields := strings.Fields(arguments)
+			if len(fields) == 0 {
+				findings = append(findings, lintFinding{line: number, severity: "error", message: "FROM has no base image"})
+			} else if fields[0] != "scratch" {
+				if problem := imageTagProblem(strings.TrimPrefix(fields[0], "--platform=")); problem != "" && !strings.HasPrefix(fields[0], "--") {
+					lintWarning(&findings, number, "%s", problem)
+				}
+			}
+		case "RUN":
+			if aptInstall.MatchString(arguments) && !strings.Contains(arguments, "-y") && !strings.Contains(arguments, "--yes") && !strings.Contains(arguments, "--assume-yes") {
+				lintWarning(&findings, number, "apt install without -y will stop at the confirmation prompt")
+			}
+			if strings.Contains(arguments, "apt-get update") && !aptInstall.MatchString(arguments) {
+				lintWarning(&findings, number, "apt-get update in its own RUN is cached separately from later installs; combine them")
+			}
+			if cdInRun.MatchString(arguments) {
+				lintWarning(&findings, number, "use WORKDIR instead of cd in RUN")
+			}
+			if strings.Contains(arguments, "sudo ") {
+				lintWarning(&findings, number, "avoid sudo in RUN; builds already run as root unless USER changed it")
+			}
+		case "ADD":
+			if !remoteOrTar.MatchString(arguments) {
+				lintWarning(&findings, number, "use COPY instead of ADD for local files")
+			}
+		case "MAINTAINER":
+			lintWarning(&findings, number, "MAINTAINER is deprecated; use LABEL maintainer=...")
+		case "CMD":
+			if lastCmd > 0 {
+				lintWarning(&findings, lastCmd, "CMD is overridden by the CMD on line %d", number)
+			}
+			lastCmd = number
+		case "ENTRYPOINT":
+			if lastEntrypoint > 0 {
+				lintWarning(&findings, lastEntrypoint, "ENTRYPOINT is overridden by the ENTRYPOINT on line %d", number)
+			}
+			lastEntrypoint = number
+		case "USER":
+			lastUser = number
+			if arguments == "root" || arguments == "0" {
+				lastUser = 0
+			}
+		}
+	}
+	if !sawFrom {
+		findings = append(findings, lintFinding{line: 1, severity: "error", message: "no FROM instruction: the Dockerfile has no base image"})
+	}
+	if sawFrom && lastUser == 0 {
+		lintWarning(&findings, len(lines), "the image runs as root; add a USER instruction")
+	}
+	return findings
+}
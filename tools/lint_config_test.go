@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		content string
+		want    []string // lines expected in the report
+	}{
+		{
+			name:    "Dockerfile without a base image",
+			file:    "Dockerfile",
+			content: "# build\nRUN make\nCOPY . /app\nCMD [\"/app/run\"]\n",
+			want:    []string{"Dockerfile:1: error: no FROM instruction", "Dockerfile:2: error: RUN before FROM"},
+		},
+		{
+			name:    "FROM without an image",
+			file:    "Dockerfile",
+			content: "FROM\nCMD [\"run\"]\n",
+			want:    []string{"Dockerfile:1: error: FROM has no base image"},
+		},
+		{
+			name:    "malformed YAML",
+			file:    "config.yaml",
+			content: "name: app\nsettings:\n\tdebug: true\nname: again\nquote: \"unterminated\n",
+			want: []string{
+				"config.yaml:3: error: tab character used for indentation",
+				"config.yaml:4: error: duplicate key \"name\"",
+				"config.yaml:5: error: unterminated",
+			},
+		},
+		{
+			name:    "valid YAML",
+			file:    "config.yaml",
+			content: "name: app\nsettings:\n  debug: true\n",
+			want:    []string{"(yaml): no problems found"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{test.file: test.content})
+			tool := &LintConfigTool{Sandbox: Sandbox{Root: dir}}
+
+			result, err := tool.Execute(context.Background(), map[string]interface{}{"path": test.file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := strings.ReplaceAll(result.Content, filepath.Join(dir, test.file), test.file)
+			for _, want := range test.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("no %q in:\n%s", want, report)
+				}
+			}
+		})
+	}
+}
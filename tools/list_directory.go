@@ -1,13 +1,16 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 )
 
 // ListDirectoryTool implements directory listing
-type ListDirectoryTool struct{}
+type ListDirectoryTool struct {
+	Sandbox
+}
 
 func (this *ListDirectoryTool) Name() string { return "list_directory" }
 func (this *ListDirectoryTool) Description() string {
@@ -26,14 +29,18 @@ func (this *ListDirectoryTool) Parameters() map[string]interface{} {
 	}
 }
 func (this *ListDirectoryTool) RequiresPermission() bool { return false }
-func (this *ListDirectoryTool) Execute(params map[string]interface{}) (string, error) {
-	path, ok := params["path"].(string)
-	if !ok || path == "" {
-		return "", fmt.Errorf("path parameter must be a non-empty string")
+func (this *ListDirectoryTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
 	}
 	entries, err := os.ReadDir(path)
 	if err != nil {
-		return "", err
+		return ToolResult{}, err
 	}
 	var result strings.Builder
 	for _, entry := range entries {
@@ -44,5 +51,5 @@ func (this *ListDirectoryTool) Execute(params map[string]interface{}) (string, e
 			result.WriteString(fmt.Sprintf("[FILE] %s (%d bytes)\n", entry.Name(), info.Size()))
 		}
 	}
-	return result.String(), nil
+	return ToolResult{Content: result.String()}, nil
 }
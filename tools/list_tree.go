@@ -1,18 +1,22 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // ListTreeTool implements recursive directory tree listing
-type ListTreeTool struct{}
+type ListTreeTool struct {
+	Sandbox
+}
 
 func (this *ListTreeTool) Name() string { return "list_tree" }
 func (this *ListTreeTool) Description() string {
-	return "List all files and directories recursively in a tree structure"
+	return "List all files and directories recursively in a tree structure, skipping entries matched by .gitignore files unless respect_gitignore is false"
 }
 func (this *ListTreeTool) Parameters() map[string]interface{} {
 	return map[string]interface{}{
@@ -26,31 +30,42 @@ func (this *ListTreeTool) Parameters() map[string]interface{} {
 				"type":        "number",
 				"description": "Maximum depth to traverse (optional, default 5)",
 			},
+			"respect_gitignore": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Skip files and directories matched by .gitignore files found in the tree (optional, default true)",
+			},
 		},
 		"required": []string{"path"},
 	}
 }
 func (this *ListTreeTool) RequiresPermission() bool { return false }
-func (this *ListTreeTool) Execute(params map[string]interface{}) (string, error) {
-	path, ok := params["path"].(string)
-	if !ok || path == "" {
-		return "", fmt.Errorf("path parameter must be a non-empty string")
+func (this *ListTreeTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
 	}
-	maxDepth := 5
-	if d, ok := params["max_depth"].(float64); ok {
-		maxDepth = int(d)
+	maxDepth, err := GetInt(params, "max_depth", 5)
+	if err != nil {
+		return ToolResult{}, err
 	}
 	var result strings.Builder
-	err := this.walkTree(path, "", 0, maxDepth, &result)
+	err = this.walkTree(ctx, path, ".", "", 0, maxDepth, respectGitignore(params), &result)
 	if err != nil {
-		return "", err
+		return ToolResult{}, err
 	}
-	return result.String(), nil
+	return ToolResult{Content: result.String()}, nil
 }
-func (this *ListTreeTool) walkTree(path, prefix string, depth, maxDepth int, result *strings.Builder) error {
+func (this *ListTreeTool) walkTree(ctx context.Context, path, relative, prefix string, depth, maxDepth int, ignore *gitignore, result *strings.Builder) error {
 	if depth > maxDepth {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	base := filepath.Base(path)
 	if base == ".git" || base == ".idea" || base == ".claude" {
 		return nil
@@ -59,6 +74,10 @@ func (this *ListTreeTool) walkTree(path, prefix string, depth, maxDepth int, res
 	if err != nil {
 		return err
 	}
+	ignore.enter(path, relative)
+	entries = slices.DeleteFunc(entries, func(entry os.DirEntry) bool {
+		return ignore.ignored(joinRelative(relative, entry.Name()), entry.IsDir())
+	})
 	for i, entry := range entries {
 		isLast := i == len(entries)-1
 		connector := "├── "
@@ -73,7 +92,7 @@ func (this *ListTreeTool) walkTree(path, prefix string, depth, maxDepth int, res
 			} else {
 				newPrefix += "│   "
 			}
-			err = this.walkTree(filepath.Join(path, entry.Name()), newPrefix, depth+1, maxDepth, result)
+			err = this.walkTree(ctx, filepath.Join(path, entry.Name()), joinRelative(relative, entry.Name()), newPrefix, depth+1, maxDepth, ignore, result)
 			if err != nil {
 				return err
 			}
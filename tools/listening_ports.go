@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ListeningPortsTool reports listening TCP sockets and, where permitted, the owning process.
+type ListeningPortsTool struct {
+	Runner   CommandRunner
+	ProcRoot string // where procfs is mounted (empty means /proc)
+}
+
+func (this *ListeningPortsTool) Name() string { return "listening_ports" }
+func (this *ListeningPortsTool) Description() string {
+	return "List listening TCP ports and the processes that own them (where permitted)"
+}
+func (this *ListeningPortsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+func (this *ListeningPortsTool) RequiresPermission() bool { return false }
+func (this *ListeningPortsTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	sockets, err := readProcNetTCP(cmp.Or(this.ProcRoot, "/proc"))
+	if err == nil {
+		return ToolResult{Content: formatListeningSockets(sockets)}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	runner := runnerOrDefault(this.Runner)
+	output, err := runner(ctx, Command{Name: "lsof", Args: []string{"-nP", "-iTCP", "-sTCP:LISTEN"}})
+	if err == nil {
+		return ToolResult{Content: string(output)}, nil
+	}
+	output, err = runner(ctx, Command{Name: "netstat", Args: []string{"-an"}})
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("unable to list listening ports (no /proc/net/tcp, lsof, or netstat available): %v", err)
+	}
+	var result strings.Builder
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.Contains(line, "LISTEN") {
+			result.WriteString(line + "\n")
+		}
+	}
+	if result.Len() == 0 {
+		return ToolResult{Content: "No listening TCP ports found."}, nil
+	}
+	return ToolResult{Content: result.String()}, nil
+}
+
+type listeningSocket struct {
+	Proto   string
+	Address string
+	Port    int
+	Inode   string
+	PID     int
+	Process string
+}
+
+const tcpListenState = "0A"
+
+func readProcNetTCP(procRoot string) (results []listeningSocket, err error) {
+	var found bool
+	for _, proto := range []string{"tcp", "tcp6"} {
+		sockets, err := parseProcNetTCP(filepath.Join(procRoot, "net", proto), proto)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		results = append(results, sockets...)
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	owners := socketOwners(procRoot)
+	for i := range results {
+		if owner, ok := owners[results[i].Inode]; ok {
+			results[i].PID = owner.PID
+			results[i].Process = owner.Process
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Port != results[j].Port {
+			return results[i].Port < results[j].Port
+		}
+		return results[i].Proto < results[j].Proto
+	})
+	return results, nil
+}
+
+func parseProcNetTCP(path, proto string) (results []listeningSocket, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		address, port, err := parseHexAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		results = append(results, listeningSocket{Proto: proto, Address: address, Port: port, Inode: fields[9]})
+	}
+	return results, scanner.Err()
+}
+
+// parseHexAddress decodes the kernel's "ADDR:PORT" notation, where the address
+// is stored as native-endian (little-endian on supported platforms) 32-bit words.
+func parseHexAddress(value string) (address string, port int, err error) {
+	hexAddress, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("malformed address: %s", value)
+	}
+	raw, err := hex.DecodeString(hexAddress)
+	if err != nil || len(raw)%4 != 0 {
+		return "", 0, fmt.Errorf("malformed address: %s", value)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	parsedPort, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	return net.IP(raw).String(), int(parsedPort), nil
+}
+
+type socketOwner struct {
+	PID     int
+	Process string
+}
+
+// socketOwners maps socket inodes to processes by scanning /proc/*/fd. Processes
+// we aren't permitted to inspect are silently skipped.
+func socketOwners(procRoot string) map[string]socketOwner {
+	owners := make(map[string]socketOwner)
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return owners
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+			comm, _ := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+			owners[inode] = socketOwner{PID: pid, Process: strings.TrimSpace(string(comm))}
+		}
+	}
+	return owners
+}
+
+func formatListeningSockets(sockets []listeningSocket) string {
+	if len(sockets) == 0 {
+		return "No listening TCP ports found."
+	}
+	var result strings.Builder
+	writer := tabwriter.NewWriter(&result, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "PROTO\tADDRESS\tPORT\tPID\tPROCESS")
+	for _, socket := range sockets {
+		pid, process := "-", "-"
+		if socket.PID > 0 {
+			pid, process = strconv.Itoa(socket.PID), socket.Process
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\n", socket.Proto, socket.Address, socket.Port, pid, process)
+	}
+	_ = writer.Flush()
+	return result.String()
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestListeningPortsShowsLocalListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	tool := &ListeningPortsTool{}
+	if _, err := readProcNetTCP("/proc"); err != nil {
+		tool.Runner = ExecRunner // no procfs here: rely on lsof or netstat
+	}
+	result, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Skipf("listening ports aren't available here: %v", err)
+	}
+	if !strings.Contains(result.Content, port) {
+		t.Errorf("port %s missing from:\n%s", port, result.Content)
+	}
+}
+
+func TestListeningPortsFallsBackToCommands(t *testing.T) {
+	cases := []struct {
+		name    string
+		outputs map[string]string // command name to output; missing commands fail
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "lsof",
+			outputs: map[string]string{"lsof": "COMMAND PID USER FD TYPE NODE NAME\nserver 42 me 3u IPv4 TCP 127.0.0.1:8080 (LISTEN)\n"},
+			want:    "127.0.0.1:8080 (LISTEN)",
+		},
+		{
+			name:    "netstat keeps only listening lines",
+			outputs: map[string]string{"netstat": "tcp4 0 0 *.8080 *.* LISTEN\ntcp4 0 0 10.0.0.1.5000 10.0.0.2.443 ESTABLISHED\n"},
+			want:    "tcp4 0 0 *.8080 *.* LISTEN\n",
+		},
+		{
+			name:    "nothing available",
+			outputs: map[string]string{},
+			wantErr: true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tool := &ListeningPortsTool{
+				ProcRoot: filepath.Join(t.TempDir(), "missing"),
+				Runner: func(ctx context.Context, command Command) ([]byte, error) {
+					output, ok := test.outputs[command.Name]
+					if !ok {
+						return nil, errors.New("executable file not found")
+					}
+					return []byte(output), nil
+				},
+			}
+			result, err := tool.Execute(context.Background(), nil)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", result.Content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(result.Content, test.want) {
+				t.Errorf("got %q, want it to contain %q", result.Content, test.want)
+			}
+			if strings.Contains(result.Content, "ESTABLISHED") {
+				t.Errorf("non-listening socket reported: %q", result.Content)
+			}
+		})
+	}
+}
+
+func TestParseHexAddress(t *testing.T) {
+	address, port, err := parseHexAddress("0100007F:1F90")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != "127.0.0.1" || port != 8080 {
+		t.Errorf("got %s:%d, want 127.0.0.1:8080", address, port)
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// liveOutput collects a command's combined output while echoing it, a line
+// at a time and set off by a gutter, to a terminal. Use the same liveOutput
+// for Stdout and Stderr so os/exec serializes the writes.
+type liveOutput struct {
+	combined bytes.Buffer
+	terminal io.Writer // nil only collects
+	partial  []byte
+	started  bool
+	label    string
+}
+
+func newLiveOutput(terminal io.Writer, label string) *liveOutput {
+	return &liveOutput{terminal: terminal, label: label}
+}
+
+func (this *liveOutput) Write(p []byte) (int, error) {
+	_, _ = this.combined.Write(p)
+	if this.terminal == nil {
+		return len(p), nil
+	}
+	if !this.started {
+		this.started = true
+		_, _ = fmt.Fprintf(this.terminal, "┌── %s\n", this.label)
+	}
+	this.partial = append(this.partial, p...)
+	for {
+		newline := bytes.IndexByte(this.partial, '\n')
+		if newline < 0 {
+			break
+		}
+		_, _ = fmt.Fprintf(this.terminal, "│ %s\n", this.partial[:newline])
+		this.partial = this.partial[newline+1:]
+	}
+	return len(p), nil
+}
+
+// Close flushes an unterminated last line and closes the gutter.
+func (this *liveOutput) Close() error {
+	if this.terminal == nil || !this.started {
+		return nil
+	}
+	if len(this.partial) > 0 {
+		_, _ = fmt.Fprintf(this.terminal, "│ %s\n", this.partial)
+		this.partial = nil
+	}
+	_, _ = fmt.Fprintln(this.terminal, "└──")
+	return nil
+}
+
+func (this *liveOutput) String() string { return this.combined.String() }
@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	logErrorLine   = regexp.MustCompile(`(?i)\b(error|err|fatal|panic|exception|critical)\b`)
+	logWarnLine    = regexp.MustCompile(`(?i)\bwarn(ing)?\b`)
+	logVariableHex = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{8,}\b`)
+	logVariableNum = regexp.MustCompile(`\d+`)
+	logQuoted      = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+const maxLogExampleLength = 300
+
+// LogSummaryTool streams a (possibly huge) log file and reports aggregate
+// counts and the most frequent patterns instead of the raw content.
+type LogSummaryTool struct {
+	Sandbox
+}
+
+func (this *LogSummaryTool) Name() string { return "log_summary" }
+func (this *LogSummaryTool) Description() string {
+	return "Summarize a log file of any size: counts of error/warning lines and the most frequent patterns with example lines. " +
+		"By default error and warning lines are grouped after masking numbers, ids, and quoted values; " +
+		"provide group_pattern (a regex, optionally with a capture group) to group matching lines by that instead."
+}
+func (this *LogSummaryTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the log file",
+			},
+			"group_pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Regular expression; lines are grouped by its first capture group (or whole match) (optional)",
+			},
+			"top_n": map[string]interface{}{
+				"type":        "number",
+				"description": "Number of top patterns to report (optional, default 10)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+func (this *LogSummaryTool) RequiresPermission() bool { return false }
+func (this *LogSummaryTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	var groupPattern *regexp.Regexp
+	pattern, err := GetOptionalString(params, "group_pattern", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if pattern != "" {
+		groupPattern, err = regexp.Compile(pattern)
+		if err != nil {
+			return ToolResult{}, fmt.Errorf("invalid group_pattern: %v", err)
+		}
+	}
+	topN, err := GetInt(params, "top_n", 10)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if topN <= 0 {
+		topN = 10
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	defer func() { _ = file.Close() }()
+	summary, err := summarizeLog(file, groupPattern)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{Content: summary.Format(path, topN)}, nil
+}
+
+type logPattern struct {
+	Key      string
+	Count    int
+	Examples []string
+}
+
+type logSummary struct {
+	Lines    int
+	Errors   int
+	Warnings int
+	patterns map[string]*logPattern
+}
+
+func summarizeLog(reader io.Reader, groupPattern *regexp.Regexp) (*logSummary, error) {
+	summary := &logSummary{patterns: make(map[string]*logPattern)}
+	buffered := bufio.NewReader(reader)
+	for {
+		line, err := buffered.ReadString('\n')
+		if len(line) > 0 {
+			summary.add(strings.TrimRight(line, "\r\n"), groupPattern)
+		}
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (this *logSummary) add(line string, groupPattern *regexp.Regexp) {
+	this.Lines++
+	isError := logErrorLine.MatchString(line)
+	isWarning := !isError && logWarnLine.MatchString(line)
+	if isError {
+		this.Errors++
+	} else if isWarning {
+		this.Warnings++
+	}
+	var key string
+	if groupPattern != nil {
+		match := groupPattern.FindStringSubmatch(line)
+		if match == nil {
+			return
+		}
+		key = match[0]
+		if len(match) > 1 {
+			key = match[1]
+		}
+	} else if isError || isWarning {
+		key = normalizeLogLine(line)
+	} else {
+		return
+	}
+	pattern, ok := this.patterns[key]
+	if !ok {
+		pattern = &logPattern{Key: key}
+		this.patterns[key] = pattern
+	}
+	pattern.Count++
+	if len(pattern.Examples) < 2 {
+		if len(line) > maxLogExampleLength {
+			line = line[:maxLogExampleLength] + "…"
+		}
+		pattern.Examples = append(pattern.Examples, line)
+	}
+}
+
+func normalizeLogLine(line string) string {
+	line = logQuoted.ReplaceAllString(line, `"…"`)
+	line = logVariableHex.ReplaceAllString(line, "<id>")
+	line = logVariableNum.ReplaceAllString(line, "#")
+	if len(line) > maxLogExampleLength {
+		line = line[:maxLogExampleLength]
+	}
+	return line
+}
+
+func (this *logSummary) Top(n int) (results []*logPattern) {
+	for _, pattern := range this.patterns {
+		results = append(results, pattern)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Key < results[j].Key
+	})
+	if len(results) > n {
+		results = results[:n]
+	}
+	return results
+}
+
+func (this *logSummary) Format(path string, topN int) string {
+	var result strings.Builder
+	_, _ = fmt.Fprintf(&result, "Log summary for %s\n", path)
+	_, _ = fmt.Fprintf(&result, "lines: %d, errors: %d, warnings: %d, distinct patterns: %d\n", this.Lines, this.Errors, this.Warnings, len(this.patterns))
+	top := this.Top(topN)
+	if len(top) == 0 {
+		return result.String()
+	}
+	_, _ = fmt.Fprintf(&result, "\nTop %d pattern(s):\n", len(top))
+	for i, pattern := range top {
+		_, _ = fmt.Fprintf(&result, "%d. [%d×] %s\n", i+1, pattern.Count, pattern.Key)
+		for _, example := range pattern.Examples {
+			_, _ = fmt.Fprintf(&result, "     e.g. %s\n", example)
+		}
+	}
+	return result.String()
+}
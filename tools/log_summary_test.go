@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLogSummary(t *testing.T) {
+	tool := &LogSummaryTool{Sandbox: Sandbox{Root: "testdata"}}
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"path": "app.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"lines: 10, errors: 5, warnings: 2, distinct patterns: 3\n",
+		"1. [4×] #-#-# #:#:# ERROR connection to db-# failed after # retries\n",
+		"     e.g. 2024-05-01 10:00:01 ERROR connection to db-1 failed after 3 retries\n",
+		"2. [2×] #-#-# #:#:# WARN slow query took #ms\n",
+		"3. [1×] #-#-# #:#:# ERROR user \"…\" not found\n",
+	}
+	for _, line := range want {
+		if !strings.Contains(result.Content, line) {
+			t.Errorf("%q missing from\n%s", line, result.Content)
+		}
+	}
+}
+
+func TestLogSummaryGroupPattern(t *testing.T) {
+	tool := &LogSummaryTool{Sandbox: Sandbox{Root: "testdata"}}
+	params := map[string]interface{}{"path": "app.log", "group_pattern": `request (\w+ \S+)`, "top_n": 1}
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, "Top 1 pattern(s):\n1. [1×] GET /health\n") {
+		t.Errorf("unexpected summary:\n%s", result.Content)
+	}
+}
@@ -1,18 +1,25 @@
 package tools
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
 // ModifyFileTool implements file modifications
-type ModifyFileTool struct{}
+type ModifyFileTool struct {
+	Sandbox
+	Session
+}
 
 func (this *ModifyFileTool) Name() string { return "modify_file" }
 func (this *ModifyFileTool) Description() string {
-	return "Modify a file by replacing the portion provided."
+	return "Modify a file by replacing the portion provided. The file must have been read in this session first. " +
+		"The result reports the number of occurrences replaced, the size change, and a diff of the edit. " +
+		"If the search text occurs more than once the edit is refused unless replace_all is true; include more surrounding context to target a single occurrence."
 }
 func (this *ModifyFileTool) Parameters() map[string]interface{} {
 	return map[string]interface{}{
@@ -30,34 +37,82 @@ func (this *ModifyFileTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "The replacement text.",
 			},
+			"replace_all": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Replace every occurrence of the search text (optional, default false).",
+			},
 		},
-		"required": []string{"path"},
+		"required": []string{"path", "search", "replace"},
 	}
 }
-func (this *ModifyFileTool) Execute(params map[string]interface{}) (string, error) {
-	path, ok := params["path"].(string)
-	if !ok {
-		return "", errors.New("path parameter must be a string")
+func (this *ModifyFileTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	search, err := GetString(params, "search")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if search == "" {
+		return ToolResult{}, errors.New("search parameter must be a non-empty string")
+	}
+	replace, err := GetString(params, "replace")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if _, err := os.Stat(path); err == nil && !this.wasRead(path) {
+		return ToolResult{}, fmt.Errorf("%s has not been read in this session; read it (e.g. with read_file) before modifying it so the edit is based on its current contents", path)
 	}
-	search, ok := params["search"].(string)
-	if !ok || search == "" {
-		return "", errors.New("search parameter must be a non-empty string")
+	debugf("modify_file: reading %s", path)
+	raw, readErr := os.ReadFile(path)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return ToolResult{}, readErr
 	}
-	replace, ok := params["replace"].(string)
-	if !ok {
-		return "", errors.New("replace parameter must be a string")
+	replaceAll, err := GetBool(params, "replace_all", false)
+	if err != nil {
+		return ToolResult{}, err
 	}
-	fmt.Println("reading file:", path)
-	raw, err := os.ReadFile(path)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return "", err
+	count := strings.Count(string(raw), search)
+	debugf("modify_file: %d occurrence(s) of the search text in %s", count, path)
+	if count > 1 && !replaceAll {
+		return ToolResult{}, fmt.Errorf("search text occurs %d times in %s (lines %s); include more surrounding context to match exactly one occurrence, or set replace_all to true",
+			count, path, strings.Join(occurrenceLines(string(raw), search), ", "))
+	}
+	if count == 0 {
+		return ToolResult{Content: fmt.Sprintf("No occurrences of the search text in %s; the file was not changed.", path)}, nil
 	}
-	fmt.Println("Contains search?", strings.Contains(string(raw), search))
 	content := strings.ReplaceAll(string(raw), search, replace)
-	fmt.Println("writing file:", path)
-	err = os.WriteFile(path, []byte(content), 0644)
-	fmt.Println("Length of old:", len(string(raw)))
-	fmt.Println("Length of new:", len(content))
-	return content, err
+	debugf("modify_file: writing %s (%d -> %d bytes)", path, len(raw), len(content))
+	if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+		return ToolResult{}, err
+	}
+	this.recordWrite(path, content)
+	this.recordEdit(path, raw, readErr)
+	summary := fmt.Sprintf("Modified %s: replaced %d occurrence(s); %d -> %d bytes (%+d).", path, count, len(raw), len(content), len(content)-len(raw))
+	if diff := unifiedDiff(path, path, string(raw), content); diff != "" {
+		summary += "\n" + diff
+	}
+	return ToolResult{Content: summary, Metadata: map[string]interface{}{MetaPath: path, MetaBytesWritten: len(content)}}, nil
 }
 func (this *ModifyFileTool) RequiresPermission() bool { return true }
+
+// occurrenceLines returns the (1-based) line numbers on which each
+// non-overlapping occurrence of search begins.
+func occurrenceLines(content, search string) (lines []string) {
+	line, offset := 1, 0
+	for {
+		index := strings.Index(content[offset:], search)
+		if index < 0 {
+			return lines
+		}
+		line += strings.Count(content[offset:offset+index], "\n")
+		lines = append(lines, strconv.Itoa(line))
+		line += strings.Count(search, "\n")
+		offset += index + len(search)
+	}
+}
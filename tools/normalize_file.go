@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// NormalizeFileTool normalizes line endings, strips a UTF-8 BOM, and ensures a trailing newline.
+type NormalizeFileTool struct {
+	Sandbox
+}
+
+func (this *NormalizeFileTool) Name() string { return "normalize_file" }
+func (this *NormalizeFileTool) Description() string {
+	return "Normalize a text file: convert line endings (lf or crlf), strip a UTF-8 byte order mark, and ensure a trailing newline. Reports what changed."
+}
+func (this *NormalizeFileTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to normalize",
+			},
+			"line_endings": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"lf", "crlf", "keep"},
+				"description": "Line endings to convert to (optional, default lf)",
+			},
+			"strip_bom": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Remove a leading UTF-8 byte order mark (optional, default true)",
+			},
+			"trailing_newline": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Ensure the file ends with a newline (optional, default true)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+func (this *NormalizeFileTool) RequiresPermission() bool { return true }
+func (this *NormalizeFileTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	lineEndings, err := GetOptionalString(params, "line_endings", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if lineEndings == "" {
+		lineEndings = "lf"
+	}
+	if lineEndings != "lf" && lineEndings != "crlf" && lineEndings != "keep" {
+		return ToolResult{}, fmt.Errorf("invalid line_endings %q (expected lf, crlf, or keep)", lineEndings)
+	}
+	stripBOM, err := GetBool(params, "strip_bom", true)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	trailingNewline, err := GetBool(params, "trailing_newline", true)
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	content := original
+	var changes []string
+	if stripBOM && bytes.HasPrefix(content, utf8BOM) {
+		content = content[len(utf8BOM):]
+		changes = append(changes, "stripped UTF-8 BOM")
+	}
+	newline := []byte("\n")
+	if lineEndings == "crlf" {
+		newline = []byte("\r\n")
+	}
+	if lineEndings != "keep" {
+		crlf := bytes.Count(content, []byte("\r\n"))
+		lf := bytes.Count(content, []byte("\n")) - crlf
+		content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+		if lineEndings == "crlf" {
+			content = bytes.ReplaceAll(content, []byte("\n"), newline)
+			if lf > 0 {
+				changes = append(changes, fmt.Sprintf("converted %d LF line ending(s) to CRLF", lf))
+			}
+		} else if crlf > 0 {
+			changes = append(changes, fmt.Sprintf("converted %d CRLF line ending(s) to LF", crlf))
+		}
+	}
+	if trailingNewline && len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, newline...)
+		changes = append(changes, "added trailing newline")
+	}
+	if bytes.Equal(content, original) {
+		return ToolResult{Content: fmt.Sprintf("%s is already normalized.", path)}, nil
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return ToolResult{}, err
+	}
+	return ToolResult{
+		Content:  fmt.Sprintf("Normalized %s: %s.", path, strings.Join(changes, ", ")),
+		Metadata: map[string]interface{}{MetaPath: path, MetaBytesWritten: len(content)},
+	}, nil
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		params  map[string]interface{}
+		want    string // the file afterwards
+		report  string // expected in the result
+	}{
+		{
+			name:    "crlf to lf",
+			content: "one\r\ntwo\r\n",
+			want:    "one\ntwo\n",
+			report:  "converted 2 CRLF line ending(s) to LF",
+		},
+		{
+			name:    "strip bom",
+			content: "\xEF\xBB\xBFpackage main\n",
+			want:    "package main\n",
+			report:  "stripped UTF-8 BOM",
+		},
+		{
+			name:    "lf to crlf with trailing newline",
+			content: "one\ntwo",
+			params:  map[string]interface{}{"line_endings": "crlf"},
+			want:    "one\r\ntwo\r\n",
+			report:  "added trailing newline",
+		},
+		{
+			name:    "already normalized",
+			content: "one\ntwo\n",
+			want:    "one\ntwo\n",
+			report:  "already normalized",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file.txt")
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			params := map[string]interface{}{"path": "file.txt"}
+			for key, value := range test.params {
+				params[key] = value
+			}
+			tool := &NormalizeFileTool{Sandbox: Sandbox{Root: dir}}
+			result, err := tool.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(result.Content, test.report) {
+				t.Errorf("result %q doesn't mention %q", result.Content, test.report)
+			}
+			content, _ := os.ReadFile(path)
+			if string(content) != test.want {
+				t.Errorf("file is %q, want %q", content, test.want)
+			}
+		})
+	}
+}
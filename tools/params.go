@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The Get* functions read a tool parameter from the arguments a model sent.
+// Models aren't strict about JSON types, so values are coerced where the
+// intent is clear: numbers and booleans written as strings ("3", "true") are
+// accepted, as are numbers where a string is expected. Anything else is an
+// error naming the parameter. An absent or null parameter yields the
+// fallback (or, for GetString, an error).
+
+// GetString returns the required string parameter key.
+func GetString(params map[string]interface{}, key string) (string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return "", fmt.Errorf("%s parameter is required", key)
+	}
+	return toString(key, raw)
+}
+
+// GetNonEmptyString returns the required string parameter key, refusing one
+// that is empty or only whitespace.
+func GetNonEmptyString(params map[string]interface{}, key string) (string, error) {
+	value, err := GetString(params, key)
+	if err == nil && strings.TrimSpace(value) == "" {
+		err = fmt.Errorf("%s parameter must be a non-empty string", key)
+	}
+	return value, err
+}
+
+// GetOptionalString returns the string parameter key, or fallback.
+func GetOptionalString(params map[string]interface{}, key, fallback string) (string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return fallback, nil
+	}
+	return toString(key, raw)
+}
+
+// GetInt returns the integer parameter key, or fallback. Fractional numbers
+// are refused rather than truncated.
+func GetInt(params map[string]interface{}, key string, fallback int) (int, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return fallback, nil
+	}
+	value, err := toFloat(key, raw)
+	if err != nil {
+		return 0, err
+	}
+	if value != math.Trunc(value) || math.Abs(value) > math.MaxInt32 {
+		return 0, fmt.Errorf("%s parameter must be a whole number, not %v", key, raw)
+	}
+	return int(value), nil
+}
+
+// GetFloat returns the numeric parameter key, or fallback.
+func GetFloat(params map[string]interface{}, key string, fallback float64) (float64, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return fallback, nil
+	}
+	return toFloat(key, raw)
+}
+
+// GetBool returns the boolean parameter key, or fallback.
+func GetBool(params map[string]interface{}, key string, fallback bool) (bool, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return fallback, nil
+	}
+	switch value := raw.(type) {
+	case bool:
+		return value, nil
+	case string:
+		if parsed, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return parsed, nil
+		}
+	case float64:
+		if value == 0 || value == 1 {
+			return value == 1, nil
+		}
+	}
+	return false, fmt.Errorf("%s parameter must be true or false, not %v", key, raw)
+}
+
+// GetStringSlice returns the array-of-strings parameter key, or nil. A lone
+// string is taken as a one-element array, or as the array it encodes when it
+// is a JSON array (e.g. "[\"a\", \"b\"]").
+func GetStringSlice(params map[string]interface{}, key string) ([]string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	switch value := raw.(type) {
+	case []string:
+		return value, nil
+	case string:
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "[") {
+			var decoded []interface{}
+			if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+				raw = decoded
+				break
+			}
+		}
+		return []string{value}, nil
+	}
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s parameter must be an array of strings", key)
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		text, err := toString(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s parameter must be an array of strings", key)
+		}
+		result = append(result, text)
+	}
+	return result, nil
+}
+
+func toString(key string, raw interface{}) (string, error) {
+	switch value := raw.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	default:
+		return "", fmt.Errorf("%s parameter must be a string", key)
+	}
+}
+
+func toFloat(key string, raw interface{}) (float64, error) {
+	switch value := raw.(type) {
+	case float64:
+		return value, nil
+	case int:
+		return float64(value), nil
+	case string:
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && !math.IsNaN(parsed) && !math.IsInf(parsed, 0) {
+			return parsed, nil
+		}
+	}
+	return 0, fmt.Errorf("%s parameter must be a number, not %v", key, raw)
+}
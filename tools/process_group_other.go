@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves cmd as is: without process groups only the
+// command itself is killed on cancellation.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	maxProfileOutputBytes  = 16 * 1024
+	defaultProfileTimeout  = 2 * time.Minute
+	maximumProfileDuration = 30 * time.Minute
+)
+
+// ProfileCommandTool runs a shell command and reports its wall-clock time, CPU
+// time, and peak memory alongside its (capped) output.
+type ProfileCommandTool struct{}
+
+func (this *ProfileCommandTool) Name() string { return "profile_command" }
+func (this *ProfileCommandTool) Description() string {
+	return "Run a shell command and report wall-clock time, user and system CPU time, and peak memory (max RSS, where the platform reports it), followed by its output"
+}
+func (this *ProfileCommandTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"command": map[string]interface{}{
+				"type":        "string",
+				"description": "The shell command to profile",
+			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": "Kill the command after this many seconds (optional, default 120)",
+			},
+		},
+		"required": []string{"command"},
+	}
+}
+func (this *ProfileCommandTool) RequiresPermission() bool { return true }
+func (this *ProfileCommandTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	command, err := GetNonEmptyString(params, "command")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	timeout, err := timeoutParam(params, defaultProfileTimeout)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	timeout = min(timeout, maximumProfileDuration)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second // don't wait on children that outlive a killed shell
+	killProcessGroupOnCancel(cmd)
+	started := time.Now()
+	runErr := cmd.Run()
+	wall := time.Since(started)
+	if cmd.ProcessState == nil {
+		return ToolResult{}, fmt.Errorf("command failed to start: %v", runErr)
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "wall time:   %s\n", wall.Round(time.Microsecond))
+	fmt.Fprintf(&report, "user CPU:    %s\n", cmd.ProcessState.UserTime().Round(time.Microsecond))
+	fmt.Fprintf(&report, "system CPU:  %s\n", cmd.ProcessState.SystemTime().Round(time.Microsecond))
+	if maxRSS, ok := maxResidentSetBytes(cmd.ProcessState); ok {
+		fmt.Fprintf(&report, "max RSS:     %.1f MiB\n", float64(maxRSS)/(1024*1024))
+	} else {
+		report.WriteString("max RSS:     unavailable on this platform\n")
+	}
+	fmt.Fprintf(&report, "exit status: %d", cmd.ProcessState.ExitCode())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		fmt.Fprintf(&report, " (killed after %s timeout)", timeout)
+	}
+	report.WriteString("\n\noutput:\n")
+	if output.Len() > maxProfileOutputBytes {
+		report.Write(output.Bytes()[:maxProfileOutputBytes])
+		fmt.Fprintf(&report, "\n[output truncated: %d bytes total]\n", output.Len())
+	} else {
+		report.Write(output.Bytes())
+	}
+	return ToolResult{Content: report.String()}, nil
+}
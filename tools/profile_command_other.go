@@ -0,0 +1,7 @@
+//go:build !unix
+
+package tools
+
+import "os"
+
+func maxResidentSetBytes(state *os.ProcessState) (int64, bool) { return 0, false }
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProfileCommandReportsTimings(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	tool := &ProfileCommandTool{}
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"command": "echo profiled; i=0; while [ $i -lt 1000 ]; do i=$((i+1)); done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{
+		`wall time:   [0-9.]+(µs|ms|s)\n`,
+		`user CPU:    [0-9.]+(µs|ms|s)?\n`,
+		`system CPU:  [0-9.]+(µs|ms|s)?\n`,
+		`max RSS:     ([0-9.]+ MiB|unavailable on this platform)\n`,
+		`exit status: 0\n`,
+	} {
+		if !regexp.MustCompile(field).MatchString(result.Content) {
+			t.Errorf("no %q in:\n%s", field, result.Content)
+		}
+	}
+	if runtime.GOOS == "linux" && strings.Contains(result.Content, "unavailable") {
+		t.Errorf("max RSS should be reported on linux:\n%s", result.Content)
+	}
+	if !strings.HasSuffix(result.Content, "output:\nprofiled\n") {
+		t.Errorf("output missing:\n%s", result.Content)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package tools
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// maxResidentSetBytes reads the peak RSS from the process's rusage.
+func maxResidentSetBytes(state *os.ProcessState) (int64, bool) {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || usage == nil {
+		return 0, false
+	}
+	maxRSS := int64(usage.Maxrss)
+	if runtime.GOOS != "darwin" && runtime.GOOS != "ios" {
+		maxRSS *= 1024 // reported in kilobytes everywhere but Apple platforms
+	}
+	return maxRSS, true
+}
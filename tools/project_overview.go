@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxOverviewTreeLines = 150
+	maxOverviewReadme    = 1500
+)
+
+// OverviewTool orients the model in a repository with one call: module
+// summary, README excerpt, git state, and a shallow file tree.
+type OverviewTool struct {
+	Sandbox
+	Runner CommandRunner
+}
+
+func (this *OverviewTool) Name() string { return "project_overview" }
+func (this *OverviewTool) Description() string {
+	return "Give a concise overview of a project: module info (go.mod or package.json), the start of the README, git status, and a depth-limited file tree that skips gitignored files. Use it first in an unfamiliar repository."
+}
+func (this *OverviewTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Project root (optional, default '.')",
+			},
+			"max_depth": map[string]interface{}{
+				"type":        "number",
+				"description": "Depth of the file tree (optional, default 2)",
+			},
+		},
+	}
+}
+func (this *OverviewTool) RequiresPermission() bool { return false }
+func (this *OverviewTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	root, err := GetOptionalString(params, "path", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if info, err := os.Stat(root); err != nil {
+		return ToolResult{}, err
+	} else if !info.IsDir() {
+		return ToolResult{}, fmt.Errorf("%s is not a directory", root)
+	}
+	maxDepth, err := GetInt(params, "max_depth", 2)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	maxDepth = max(maxDepth, 1)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	runner := runnerOrDefault(this.Runner)
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "# Project overview: %s\n", root)
+	if module := moduleSummary(root); module != "" {
+		report.WriteString("\n## Module\n" + module)
+	}
+	if readme := readmeExcerpt(root); readme != "" {
+		report.WriteString("\n## README\n" + readme)
+	}
+	if info, err := gatherGitInfo(ctx, runner, root); err == nil {
+		report.WriteString("\n## Git\n" + info.String())
+	}
+	files, err := projectFiles(ctx, runner, root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	fmt.Fprintf(&report, "\n## Tree (depth %d, %d files)\n", maxDepth, len(files))
+	report.WriteString(renderFileTree(files, maxDepth))
+	return ToolResult{Content: report.String()}, nil
+}
+
+// moduleSummary describes go.mod and package.json, when present.
+func moduleSummary(root string) string {
+	var summary strings.Builder
+	if content, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		var module, version string
+		var requires int
+		inRequire := false
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "module "):
+				module = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+			case strings.HasPrefix(line, "go "):
+				version = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+			case line == "require (":
+				inRequire = true
+			case inRequire && line == ")":
+				inRequire = false
+			case inRequire && line != "" && !strings.HasPrefix(line, "//"):
+				requires++
+			case strings.HasPrefix(line, "require "):
+				requires++
+			}
+		}
+		fmt.Fprintf(&summary, "go.mod: module %s, go %s, %d requirement(s)\n", module, version, requires)
+	}
+	if content, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			Name            string            `json:"name"`
+			Version         string            `json:"version"`
+			Scripts         map[string]string `json:"scripts"`
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(content, &pkg); err != nil {
+			fmt.Fprintf(&summary, "package.json: unreadable (%v)\n", err)
+		} else {
+			var scripts []string
+			for name := range pkg.Scripts {
+				scripts = append(scripts, name)
+			}
+			sort.Strings(scripts)
+			fmt.Fprintf(&summary, "package.json: %s %s, %d dependencies, %d devDependencies, scripts: %s\n",
+				pkg.Name, pkg.Version, len(pkg.Dependencies), len(pkg.DevDependencies), strings.Join(scripts, ", "))
+		}
+	}
+	return summary.String()
+}
+
+func readmeExcerpt(root string) string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(strings.ToLower(entry.Name()), "readme") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, entry.Name()))
+		if err != nil {
+			continue
+		}
+		text := strings.TrimSpace(string(content))
+		if len(text) > maxOverviewReadme {
+			text = text[:maxOverviewReadme] + "\n[...]"
+		}
+		return fmt.Sprintf("(%s)\n%s\n", entry.Name(), text)
+	}
+	return ""
+}
+
+// projectFiles lists the files under root relative to it, honoring .gitignore
+// when root is in a git work tree.
+func projectFiles(ctx context.Context, runner CommandRunner, root string) (files []string, err error) {
+	output, gitErr := runner(ctx, Command{Dir: root, Name: "git", Args: []string{"ls-files", "--cached", "--others", "--exclude-standard"}})
+	if gitErr == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files, line)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relative))
+		return nil
+	})
+	if errors.Is(err, fs.ErrPermission) {
+		err = nil
+	}
+	return files, err
+}
+
+type fileTreeNode struct {
+	children map[string]*fileTreeNode
+	files    int // files anywhere below this directory
+}
+
+// renderFileTree draws the files as a tree down to maxDepth, summarizing deeper
+// directories by their file counts.
+func renderFileTree(files []string, maxDepth int) string {
+	root := &fileTreeNode{children: make(map[string]*fileTreeNode)}
+	for _, file := range files {
+		node := root
+		for _, part := range strings.Split(file, "/") {
+			node.files++
+			child, ok := node.children[part]
+			if !ok {
+				child = &fileTreeNode{children: make(map[string]*fileTreeNode)}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+	var lines []string
+	var walk func(node *fileTreeNode, prefix string, depth int)
+	walk = func(node *fileTreeNode, prefix string, depth int) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			child := node.children[name]
+			connector, indent := "├── ", "│   "
+			if i == len(names)-1 {
+				connector, indent = "└── ", "    "
+			}
+			switch {
+			case len(child.children) == 0:
+				lines = append(lines, prefix+connector+name)
+			case depth >= maxDepth:
+				lines = append(lines, fmt.Sprintf("%s%s%s/ (%d files)", prefix, connector, name, child.files))
+			default:
+				lines = append(lines, prefix+connector+name+"/")
+				walk(child, prefix+indent, depth+1)
+			}
+		}
+	}
+	walk(root, "", 1)
+	if len(lines) > maxOverviewTreeLines {
+		omitted := len(lines) - maxOverviewTreeLines
+		lines = append(lines[:maxOverviewTreeLines], fmt.Sprintf("[%d more lines; use list_tree on a subdirectory]", omitted))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
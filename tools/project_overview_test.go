@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProjectOverview(t *testing.T) {
+	dir := newGitRepo(t, "initial commit")
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":                 "module example.com/widget\n\ngo 1.25\n\nrequire (\n\tgolang.org/x/text v0.20.0\n)\n",
+		"README.md":              "# Widget\n\nWidget renders widgets.\n" + strings.Repeat("More detail. ", 200),
+		".gitignore":             "build/\n",
+		"build/widget":           "binary",
+		"cmd/widget/main.go":     "package main\n",
+		"internal/render/a.go":   "package render\n",
+		"internal/render/b/b.go": "package b\n",
+	})
+	tool := &OverviewTool{Sandbox: Sandbox{Root: dir}}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"go.mod: module example.com/widget, go 1.25, 1 requirement(s)",
+		"(README.md)\n# Widget\n\nWidget renders widgets.",
+		"[...]",
+		"branch: main",
+		"## Tree (depth 2, 7 files)\n",
+		"├── cmd/\n│   └── widget/ (1 files)\n",
+		"└── internal/\n    └── render/ (2 files)\n",
+	} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("no %q in:\n%s", want, result.Content)
+		}
+	}
+	if strings.Contains(result.Content, "build") {
+		t.Errorf("the gitignored build directory is listed:\n%s", result.Content)
+	}
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const requirementsMarker = ".cli-ai-agent-requirements"
+
+// requirementsParam reads the optional requirements parameter: pip
+// requirement specifiers such as "pandas" or "requests>=2.31". Options
+// (anything starting with '-') are refused so a requirement can't redirect
+// pip to another index or install from an arbitrary location.
+func requirementsParam(params map[string]interface{}) ([]string, error) {
+	values, err := GetStringSlice(params, "requirements")
+	if err != nil {
+		return nil, fmt.Errorf("requirements parameter must be an array of package names")
+	}
+	var requirements []string
+	for _, value := range values {
+		requirement := strings.TrimSpace(value)
+		if requirement == "" || strings.HasPrefix(requirement, "-") {
+			return nil, fmt.Errorf("invalid requirement %v: expected a package specifier like \"requests\" or \"numpy>=1.26\"", value)
+		}
+		requirements = append(requirements, requirement)
+	}
+	slices.Sort(requirements)
+	return slices.Compact(requirements), nil
+}
+
+// pythonVenv returns the interpreter of a virtualenv with requirements
+// installed, creating it under cacheDir on first use and reusing it for later
+// calls with the same requirement set. The log reports what was done.
+func pythonVenv(ctx context.Context, cacheDir string, requirements []string) (python, log string, err error) {
+	if cacheDir == "" {
+		if cacheDir, err = os.UserCacheDir(); err != nil {
+			return "", "", err
+		}
+		cacheDir = filepath.Join(cacheDir, "cli-ai-agent", "venvs")
+	}
+	key := strings.Join(requirements, "\n")
+	sum := sha256.Sum256([]byte(key))
+	venv := filepath.Join(cacheDir, hex.EncodeToString(sum[:8]))
+	python = filepath.Join(venv, "bin", "python")
+	if runtime.GOOS == "windows" {
+		python = filepath.Join(venv, "Scripts", "python.exe")
+	}
+	if installed, err := os.ReadFile(filepath.Join(venv, requirementsMarker)); err == nil && string(installed) == key {
+		return python, fmt.Sprintf("using cached environment %s\n", venv), nil
+	}
+
+	// Start over: a venv without the marker is left over from a failed install.
+	if err = os.RemoveAll(venv); err != nil {
+		return "", "", err
+	}
+	if err = os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", "", err
+	}
+	var output strings.Builder
+	run := func(name string, args ...string) error {
+		_, _ = fmt.Fprintf(&output, "$ %s %s\n", name, strings.Join(args, " "))
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		cmd.WaitDelay = time.Second
+		killProcessGroupOnCancel(cmd)
+		return cmd.Run()
+	}
+	if err = run("python3", "-m", "venv", venv); err != nil {
+		return "", output.String(), fmt.Errorf("creating virtualenv failed: %w", err)
+	}
+	if err = run(python, slices.Concat([]string{"-m", "pip", "install", "--disable-pip-version-check", "--quiet"}, requirements)...); err != nil {
+		_ = os.RemoveAll(venv)
+		return "", output.String(), fmt.Errorf("installing requirements failed: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(venv, requirementsMarker), []byte(key), 0644); err != nil {
+		return "", output.String(), err
+	}
+	return python, output.String(), nil
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
@@ -10,7 +11,14 @@ import (
 	"unicode/utf8"
 )
 
+const (
+	defaultMaxFiles      = 200
+	defaultMaxTotalBytes = 512 * 1024
+)
+
 type ReadAllFilesInDirectoryTool struct {
+	Sandbox
+	Session
 }
 
 func (this *ReadAllFilesInDirectoryTool) Name() string {
@@ -18,7 +26,10 @@ func (this *ReadAllFilesInDirectoryTool) Name() string {
 }
 
 func (this *ReadAllFilesInDirectoryTool) Description() string {
-	return "Given a path to a folder, recursively read all text (code) files."
+	return "Given a path to a folder, recursively read all text (code) files (up to 64KB each and 512KB in total by default). " +
+		"Optional include/exclude glob patterns are matched against each path relative to the folder; " +
+		"'**' matches any number of directories (e.g. '**/*.go', '**/vendor/**') and a pattern without '/' matches file names. " +
+		"Excluded directories are skipped entirely, as are entries matched by .gitignore files unless respect_gitignore is false."
 }
 
 func (this *ReadAllFilesInDirectoryTool) Parameters() map[string]interface{} {
@@ -29,41 +40,133 @@ func (this *ReadAllFilesInDirectoryTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "Path to the directory with files to read (recursively).",
 			},
+			"max_files": map[string]interface{}{
+				"type":        "number",
+				"description": "Maximum number of files to read (optional, default 200).",
+			},
+			"max_total_bytes": map[string]interface{}{
+				"type":        "number",
+				"description": "Stop once the combined output reaches this many bytes (optional, default 524288).",
+			},
+			"include": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only read files whose relative path matches one of these globs (optional), e.g. [\"**/*.go\"].",
+			},
+			"exclude": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Skip files and directories whose relative path matches one of these globs (optional), e.g. [\"**/vendor/**\"].",
+			},
+			"respect_gitignore": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Skip files and directories matched by .gitignore files found in the tree (optional, default true).",
+			},
 		},
 		"required": []string{"path"},
 	}
 }
 
-func (this *ReadAllFilesInDirectoryTool) Execute(params map[string]interface{}) (string, error) {
-	root, ok := params["path"].(string)
-	if !ok || root == "" {
-		return "", fmt.Errorf("path parameter must be a non-empty string")
+func (this *ReadAllFilesInDirectoryTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	root, err := GetNonEmptyString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	maxFiles, err := GetInt(params, "max_files", defaultMaxFiles)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultMaxFiles
+	}
+	maxTotalBytes, err := GetInt(params, "max_total_bytes", defaultMaxTotalBytes)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if maxTotalBytes <= 0 {
+		maxTotalBytes = defaultMaxTotalBytes
+	}
+	include, err := globsParam(params, "include")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	exclude, err := globsParam(params, "exclude")
+	if err != nil {
+		return ToolResult{}, err
 	}
+	ignore := respectGitignore(params)
 	var result strings.Builder
-	err := filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+	var filesRead, filesSkipped int
+	var truncated bool
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			if strings.HasPrefix(info.Name(), ".") && len(info.Name()) > 1 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(root, path)
+		relative = filepath.ToSlash(relative)
+		if entry.IsDir() {
+			if relative != "." && (strings.HasPrefix(entry.Name(), ".") || matchAnyGlob(exclude, relative) || ignore.ignored(relative, true)) {
 				return filepath.SkipDir
 			}
+			ignore.enter(path, relative)
+			return nil
+		}
+		if ignore.ignored(relative, false) || matchAnyGlob(exclude, relative) || (len(include) > 0 && !matchAnyGlob(include, relative)) {
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if _, err := this.Resolve(path); err != nil {
+				return nil // a link pointing outside the sandbox
+			}
+		}
+		if filesRead >= maxFiles {
+			filesSkipped++
 			return nil
 		}
+		header := fmt.Sprintf("\n\nFile at: %s\n\n", path)
+		if result.Len()+len(header) >= maxTotalBytes {
+			truncated = true
+			return filepath.SkipAll
+		}
+		filesRead++
 		file, err := os.Open(path)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = file.Close() }()
-		_, _ = fmt.Fprintf(&result, "\n\nFile at: %s\n\n", path)
+		_, _ = result.WriteString(header)
 		reader := io.LimitReader(file, 1024*64)
 		content, _ := io.ReadAll(reader)
-		if utf8.Valid(content) {
-			_, _ = result.Write(content)
+		if !utf8.Valid(content) {
+			return nil
 		}
+		if remaining := maxTotalBytes - result.Len(); len(content) > remaining {
+			content = content[:remaining]
+			for len(content) > 0 && !utf8.Valid(content) {
+				content = content[:len(content)-1] // don't split a multi-byte character
+			}
+			truncated = true
+		}
+		_, _ = result.Write(content)
+		if truncated {
+			return filepath.SkipAll
+		}
+		this.markRead(path)
 		return nil
 	})
-	return result.String(), err
+	if truncated {
+		_, _ = fmt.Fprintf(&result, "\n\n[truncated: output limit reached (max_total_bytes %d) after %d files; narrow the path or use include/exclude]\n", maxTotalBytes, filesRead)
+	} else if filesSkipped > 0 {
+		_, _ = fmt.Fprintf(&result, "\n\n[max_files limit (%d) reached: %d more files not read]\n", maxFiles, filesSkipped)
+	}
+	return ToolResult{Content: result.String(), Metadata: map[string]interface{}{MetaTruncated: truncated}}, err
 }
 
 func (this *ReadAllFilesInDirectoryTool) RequiresPermission() bool {
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadAllFilesStopsAtMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 300 {
+		name := filepath.Join(dir, fmt.Sprintf("file%03d.txt", i))
+		if err := os.WriteFile(name, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := &ReadAllFilesInDirectoryTool{}
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"path": dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read := strings.Count(result.Content, "File at: "); read != 200 {
+		t.Errorf("read %d files, want 200", read)
+	}
+	if !strings.Contains(result.Content, "[max_files limit (200) reached: 100 more files not read]") {
+		t.Errorf("skipped count missing from the end of:\n...%s", result.Content[len(result.Content)-200:])
+	}
+}
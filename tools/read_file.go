@@ -1,16 +1,21 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // ReadFileTool implements file reading
-type ReadFileTool struct{}
+type ReadFileTool struct {
+	Sandbox
+	Session
+}
 
 func (this *ReadFileTool) Name() string { return "read_file" }
 func (this *ReadFileTool) Description() string {
-	return "Read the contents of a file"
+	return "Read the contents of a file, or just the inclusive 1-indexed range start_line..end_line of it (prefixed with line numbers, e.g. '42: func foo() {')"
 }
 func (this *ReadFileTool) Parameters() map[string]interface{} {
 	return map[string]interface{}{
@@ -20,19 +25,73 @@ func (this *ReadFileTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "Path to the file to read",
 			},
+			"start_line": map[string]interface{}{
+				"type":        "integer",
+				"description": "First line to return, 1-indexed (optional, default 1)",
+			},
+			"end_line": map[string]interface{}{
+				"type":        "integer",
+				"description": "Last line to return, inclusive (optional, default the end of the file)",
+			},
+			"with_line_numbers": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Prefix each line with its number when reading the whole file (optional, default false; ranges are always numbered)",
+			},
 		},
 		"required": []string{"path"},
 	}
 }
 func (this *ReadFileTool) RequiresPermission() bool { return false }
-func (this *ReadFileTool) Execute(params map[string]interface{}) (string, error) {
-	path, ok := params["path"].(string)
-	if !ok {
-		return "", fmt.Errorf("path parameter must be a string")
+func (this *ReadFileTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
 	}
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return ToolResult{}, err
+	}
+	this.markRead(path)
+	hasStart, hasEnd := params["start_line"] != nil, params["end_line"] != nil
+	start, err := GetInt(params, "start_line", 1)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	end, err := GetInt(params, "end_line", 0)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	numbered, err := GetBool(params, "with_line_numbers", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if !hasStart && !hasEnd && !numbered {
+		return ToolResult{Content: string(content)}, nil
+	}
+	lines := splitLines(string(content))
+	first, last := 1, len(lines)
+	if hasStart {
+		first = max(start, 1)
+	}
+	if hasEnd {
+		last = min(end, len(lines))
+	}
+	if first > len(lines) {
+		return ToolResult{Content: fmt.Sprintf("[%s has %d lines; start_line %d is past the end]", path, len(lines), first)}, nil
+	}
+	if first > last {
+		return ToolResult{}, fmt.Errorf("end_line %d is before start_line %d", last, first)
+	}
+	var result strings.Builder
+	for number := first; number <= last; number++ {
+		_, _ = fmt.Fprintf(&result, "%d: %s\n", number, lines[number-1])
+	}
+	if (hasStart && int(start) < 1) || (hasEnd && int(end) > len(lines)) {
+		_, _ = fmt.Fprintf(&result, "[showing lines %d-%d; %s has %d lines]\n", first, last, path, len(lines))
 	}
-	return string(content), nil
+	return ToolResult{Content: result.String()}, nil
 }
@@ -1,16 +1,27 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"slices"
+	"strings"
+	"time"
 )
 
-// RunCommandTool implements shell command execution
-type RunCommandTool struct{}
+// RunCommandTool implements shell command execution. Its name is
+// run_shell_command (not run_command), which the default -core-tools and the
+// other tools' descriptions rely on.
+type RunCommandTool struct {
+	Runner CommandRunner // used for dry-run previews
+	Output io.Writer     // where output is shown live as the command runs (nil disables)
+}
 
 func (this *RunCommandTool) Name() string { return "run_shell_command" }
 func (this *RunCommandTool) Description() string {
-	return "Execute a shell command and return its output"
+	return "Execute a shell command and return its output. The command (and anything it started) is killed after timeout_seconds (default 30)"
 }
 func (this *RunCommandTool) Parameters() map[string]interface{} {
 	return map[string]interface{}{
@@ -20,20 +31,151 @@ func (this *RunCommandTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "The shell command to execute",
 			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "number",
+				"description": "Kill the command after this many seconds (optional, default 30)",
+			},
+			"working_dir": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory to run the command in (optional, default the current directory)",
+			},
+			"env": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Environment variables to set, e.g. {\"CGO_ENABLED\": \"0\"}; they supplement (and override) the inherited environment (optional)",
+			},
+			"clear_env": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Start from an empty environment instead of inheriting one, so only env is set (optional, default false)",
+			},
 		},
 		"required": []string{"command"},
 	}
 }
 func (this *RunCommandTool) RequiresPermission() bool { return true }
-func (this *RunCommandTool) Execute(params map[string]interface{}) (string, error) {
-	command, ok := params["command"].(string)
-	if !ok || command == "" {
-		return "", fmt.Errorf("command parameter must be a non-empty string")
+func (this *RunCommandTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	command, err := GetNonEmptyString(params, "command")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	dir, err := workingDirParam(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	env, err := envParam(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	timeout, err := timeoutParam(params, defaultCommandTimeout)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = env
+	output := newLiveOutput(this.Output, "$ "+command)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = time.Second // don't wait on children that outlive a killed shell
+	killProcessGroupOnCancel(cmd)
+	err = cmd.Run()
+	_ = output.Close()
+	result := ToolResult{
+		Content:  withWorkingDirHeader(dir, output.String()),
+		Metadata: commandMetadata(ctx, cmd),
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("command timed out after %s; output so far:\n%s", timeout, result.Content)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return result, fmt.Errorf("command canceled (%v); output so far:\n%s", context.Cause(ctx), result.Content)
+	}
+	if err != nil {
+		return result, fmt.Errorf("command failed: %v\n%s", err, result.Content)
+	}
+	return result, nil
+}
+
+// commandMetadata describes how a finished command ended.
+func commandMetadata(ctx context.Context, cmd *exec.Cmd) map[string]interface{} {
+	return map[string]interface{}{
+		MetaExitCode: cmd.ProcessState.ExitCode(), // -1 if it never started or was killed
+		MetaTimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+}
+
+const (
+	defaultCommandTimeout = 30 * time.Second
+	dryRunPreviewTimeout  = 10 * time.Second
+)
+
+// DryRunPreview runs the side-effect-free variant of a recognized git command
+// (add, rm, mv, commit or clean with --dry-run) in the call's working_dir, so
+// its output can be shown before approval. The preview runs before anything is
+// approved, so only options known to be harmless are accepted, and the call's
+// env is left out (GIT_CONFIG_*, GIT_SSH_COMMAND and the like can run
+// programs). Commands involving any shell syntax are never previewed, nor are
+// push and fetch (hooks, --receive-pack and the network), rsync (-e and
+// --rsync-path start programs), or make (-n still runs recipe lines marked '+'
+// or calling $(MAKE), and expands $(shell ...)).
+func (this *RunCommandTool) DryRunPreview(params map[string]interface{}) (string, bool) {
+	command, _ := GetOptionalString(params, "command", "")
+	args, ok := dryRunArgs(command)
+	if !ok {
+		return "", false
 	}
-	cmd := exec.Command("sh", "-c", command)
-	output, err := cmd.CombinedOutput()
+	dir, err := workingDirParam(params)
 	if err != nil {
-		return string(output), fmt.Errorf("command failed: %v\n%s", err, string(output))
+		return "", false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dryRunPreviewTimeout)
+	defer cancel()
+	output, err := runnerOrDefault(this.Runner)(ctx, Command{Dir: dir, Name: args[0], Args: args[1:]})
+	preview := "$ " + strings.Join(args, " ") + "\n" + string(output)
+	if err != nil {
+		preview += fmt.Sprintf("\n(dry run failed: %v)", err)
+	}
+	return preview, true
+}
+
+// gitDryRunOptions lists the git subcommands that --dry-run makes side-effect
+// free, with the options a previewed call may use. Any other option (-c,
+// --exec, --pathspec-from-file, ...) means the command isn't previewed.
+var gitDryRunOptions = map[string][]string{
+	"add":    {"-A", "--all", "-u", "--update", "-f", "--force", "-v", "--verbose", "-n", "--dry-run", "--"},
+	"rm":     {"-r", "-f", "--force", "--cached", "-q", "--quiet", "-n", "--dry-run", "--"},
+	"mv":     {"-f", "--force", "-k", "-v", "--verbose", "-n", "--dry-run", "--"},
+	"commit": {"-a", "--all", "--amend", "-m", "-v", "--verbose", "--short", "--dry-run", "--"},
+	"clean":  {"-d", "-f", "--force", "-x", "-X", "-n", "--dry-run", "--"},
+}
+
+func dryRunArgs(command string) ([]string, bool) {
+	if strings.ContainsAny(command, ";|&<>`$()\\\"'\n*?") {
+		return nil, false
+	}
+	args := strings.Fields(command)
+	if len(args) < 2 || args[0] != "git" {
+		return nil, false
+	}
+	allowed, ok := gitDryRunOptions[args[1]]
+	if !ok {
+		return nil, false
+	}
+	for _, arg := range args[2:] {
+		if strings.HasPrefix(arg, "-") && !slices.Contains(allowed, arg) {
+			return nil, false
+		}
+	}
+	return withFlag(args, 2, "--dry-run", ""), true // -n means something else for some subcommands
+}
+
+// withFlag inserts flag at position at unless the arguments already contain it
+// (or its short form, when given).
+func withFlag(args []string, at int, flag, short string) []string {
+	if slices.Contains(args[at:], flag) || (short != "" && slices.Contains(args[at:], short)) {
+		return args
 	}
-	return string(output), nil
+	return slices.Concat(args[:at], []string{flag}, args[at:])
 }
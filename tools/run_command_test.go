@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDryRunArgs(t *testing.T) {
+	cases := map[string][]string{ // nil: not previewed
+		"git add src":                             {"git", "add", "--dry-run", "src"},
+		"git commit --dry-run":                    {"git", "commit", "--dry-run"},
+		"git rm -r --cached build":                {"git", "rm", "--dry-run", "-r", "--cached", "build"},
+		"git push origin main":                    nil,
+		"git fetch origin":                        nil,
+		"git push --receive-pack=evil origin":     nil,
+		"git fetch --upload-pack=evil origin":     nil,
+		"git -c core.fsmonitor=evil add src":      nil,
+		"git add --pathspec-from-file=list":       nil,
+		"git commit --exec=evil":                  nil,
+		"rsync -a src/ dest/":                     nil,
+		"rsync -e evil -a src/ dest/":             nil,
+		"rsync --rsh=evil --rsync-path=evil a b/": nil,
+		"make install":                            nil,
+		"make -n install":                         nil,
+		"git status":                              nil,
+		"git add $(ls)":                           nil,
+		"rm -rf build":                            nil,
+	}
+	for command, want := range cases {
+		got, ok := dryRunArgs(command)
+		if ok != (want != nil) || !slices.Equal(got, want) {
+			t.Errorf("dryRunArgs(%q) = %q, %v; want %q", command, got, ok, want)
+		}
+	}
+}
+
+func TestDryRunPreviewUsesWorkingDirButNotEnv(t *testing.T) {
+	dir := t.TempDir()
+	var ran Command
+	tool := &RunCommandTool{Runner: func(ctx context.Context, command Command) ([]byte, error) {
+		ran = command
+		return []byte("add 'src/main.go'\n"), nil
+	}}
+	params := map[string]interface{}{
+		"command":     "git add src",
+		"working_dir": dir,
+		"env":         map[string]interface{}{"GIT_DIR": "/elsewhere/.git"},
+		"clear_env":   true,
+	}
+	preview, ok := tool.DryRunPreview(params)
+	if !ok {
+		t.Fatal("expected a preview")
+	}
+	if preview != "$ git add --dry-run src\nadd 'src/main.go'\n" {
+		t.Errorf("unexpected preview %q", preview)
+	}
+	if ran.Dir != dir || len(ran.Env) > 0 {
+		t.Errorf("the preview ran in %q with %q", ran.Dir, ran.Env)
+	}
+
+	params["working_dir"] = dir + "/missing"
+	if preview, ok := tool.DryRunPreview(params); ok {
+		t.Errorf("previewed in a missing directory: %q", preview)
+	}
+	if _, ok := tool.DryRunPreview(map[string]interface{}{"command": "make all"}); ok {
+		t.Error("make was previewed")
+	}
+	if preview, _ := tool.DryRunPreview(map[string]interface{}{"command": "git add ."}); !strings.HasPrefix(preview, "$ git add --dry-run .") {
+		t.Errorf("unexpected preview %q", preview)
+	}
+}
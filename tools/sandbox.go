@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox confines the paths a tool may touch to a root directory. The zero
+// value imposes no restriction. Tools embed a Sandbox so the agent can inject
+// the configured root via SetSandbox.
+type Sandbox struct {
+	Root string
+}
+
+// NewSandbox resolves root (following symlinks) so later comparisons are exact.
+func NewSandbox(root string) (Sandbox, error) {
+	if root == "" {
+		return Sandbox{}, nil
+	}
+	absolute, err := filepath.Abs(root)
+	if err != nil {
+		return Sandbox{}, err
+	}
+	resolved, err := filepath.EvalSymlinks(absolute)
+	if err != nil {
+		return Sandbox{}, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return Sandbox{}, err
+	}
+	if !info.IsDir() {
+		return Sandbox{}, fmt.Errorf("sandbox root is not a directory: %s", root)
+	}
+	return Sandbox{Root: resolved}, nil
+}
+
+func (this *Sandbox) SetSandbox(sandbox Sandbox) { *this = sandbox }
+
+// SandboxAware is implemented by tools that embed a Sandbox. The agent injects
+// its configured root when the tool is registered.
+type SandboxAware interface {
+	SetSandbox(sandbox Sandbox)
+}
+
+// Resolve returns the path to use for the given path parameter, or an error if
+// it escapes the sandbox root (via '..' or symlinks). Relative paths are
+// interpreted relative to the root.
+func (this Sandbox) Resolve(path string) (string, error) {
+	if this.Root == "" {
+		return path, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(this.Root, path)
+	}
+	resolved, err := evalExistingSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	if !this.contains(resolved) {
+		return "", fmt.Errorf("path %q is outside the sandbox root %q", path, this.Root)
+	}
+	return resolved, nil
+}
+
+func (this Sandbox) contains(path string) bool {
+	relative, err := filepath.Rel(this.Root, path)
+	if err != nil {
+		return false
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// evalExistingSymlinks resolves symlinks in the longest existing prefix of
+// path, then re-appends the components that don't exist yet.
+func evalExistingSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	resolvedParent, err := evalExistingSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
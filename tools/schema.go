@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ValidationError lists what is wrong with a tool call's arguments.
+type ValidationError struct {
+	Problems []string
+}
+
+func (this *ValidationError) Error() string {
+	return "invalid arguments:\n- " + strings.Join(this.Problems, "\n- ") +
+		"\nFix the arguments to match the tool's parameters and call it again."
+}
+
+// ValidateParams checks params against a tool's Parameters schema: required
+// parameters must be present (and not null), and declared parameters must
+// have their declared type (allowing the coercions of the Get* functions)
+// and, when the schema lists an enum, one of its values. Parameters the
+// schema doesn't declare are left alone. The result is a *ValidationError,
+// or nil.
+func ValidateParams(schema, params map[string]interface{}) error {
+	var problems []string
+	for _, key := range schemaStrings(schema["required"]) {
+		if params[key] == nil {
+			problems = append(problems, fmt.Sprintf("%s: required parameter is missing", key))
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		property, _ := properties[key].(map[string]interface{})
+		if params[key] == nil || property == nil {
+			continue
+		}
+		if problem := checkType(params, key, property); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkType describes how the parameter key fails to match property, or
+// returns "".
+func checkType(params map[string]interface{}, key string, property map[string]interface{}) string {
+	var err error
+	switch property["type"] {
+	case "string":
+		var value string
+		if value, err = GetString(params, key); err == nil {
+			if allowed := schemaStrings(property["enum"]); len(allowed) > 0 && !slices.Contains(allowed, value) {
+				return fmt.Sprintf("%q is not one of %s", value, strings.Join(allowed, ", "))
+			}
+		}
+	case "integer":
+		_, err = GetInt(params, key, 0)
+	case "number":
+		_, err = GetFloat(params, key, 0)
+	case "boolean":
+		_, err = GetBool(params, key, false)
+	case "array":
+		items, _ := property["items"].(map[string]interface{})
+		if items == nil || items["type"] == "string" {
+			_, err = GetStringSlice(params, key)
+		} else if _, ok := params[key].([]interface{}); !ok {
+			return "must be an array"
+		}
+	case "object":
+		if _, ok := params[key].(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+	}
+	if err != nil {
+		return strings.TrimPrefix(err.Error(), key+" parameter ")
+	}
+	return ""
+}
+
+// schemaStrings reads a list of strings from a schema, which may have been
+// built in Go ([]string) or decoded from JSON ([]interface{}).
+func schemaStrings(raw interface{}) []string {
+	switch values := raw.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		var results []string
+		for _, value := range values {
+			if text, ok := value.(string); ok {
+				results = append(results, text)
+			}
+		}
+		return results
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScriptTool runs a script through an interpreter, e.g.
+//
+//	agent.RegisterTool(tools.NewScriptTool("node", []string{"-e"}, "execute_javascript", "Execute a JavaScript program with Node.js and return its output"))
+//
+// The script follows the interpreter arguments, so those should end with the
+// interpreter's inline-program flag (-e, -c, ...). Alternatively, set
+// FileExtension and leave the arguments empty to have the script written to a
+// temporary file in the working directory whose path is passed instead, which
+// gives error messages real file names and line numbers and lets the script
+// import the project's modules. Every ScriptTool asks permission before
+// running and shares the timeout, working_dir, env and args handling.
+type ScriptTool struct {
+	Interpreter     string
+	InterpreterArgs []string
+	FileExtension   string // e.g. ".py"; when set the script is run from a temporary file in the working directory
+
+	name        string
+	description string
+
+	// setup optionally prepares the run (e.g. installing dependencies) within
+	// the timeout, possibly choosing a different interpreter; its log is
+	// reported ahead of the script's output.
+	setup        func(ctx context.Context, params map[string]interface{}) (interpreter, log string, err error)
+	setupHeading string
+}
+
+func NewScriptTool(interpreter string, args []string, name, description string) *ScriptTool {
+	return &ScriptTool{Interpreter: interpreter, InterpreterArgs: args, name: name, description: description}
+}
+
+func (this *ScriptTool) Name() string        { return this.name }
+func (this *ScriptTool) Description() string { return this.description }
+func (this *ScriptTool) Parameters() map[string]interface{} {
+	language := this.language()
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"script": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("The %s code to execute", language),
+			},
+			"args": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Command-line arguments for the script (optional)",
+			},
+			"timeout_seconds": map[string]interface{}{
+				"type":        "number",
+				"description": "Kill the script after this many seconds (optional, default 30)",
+			},
+			"working_dir": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory to run the script in (optional, default the current directory)",
+			},
+			"env": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Environment variables to set, e.g. {\"CGO_ENABLED\": \"0\"}; they supplement (and override) the inherited environment (optional)",
+			},
+			"clear_env": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Start from an empty environment instead of inheriting one, so only env is set (optional, default false)",
+			},
+		},
+		"required": []string{"script"},
+	}
+}
+func (this *ScriptTool) RequiresPermission() bool { return true }
+func (this *ScriptTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	script, err := GetNonEmptyString(params, "script")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	dir, err := workingDirParam(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	env, err := envParam(params)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	args, err := GetStringSlice(params, "args")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	timeout, err := timeoutParam(params, defaultCommandTimeout)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interpreter, setupLog := this.Interpreter, ""
+	if this.setup != nil {
+		interpreter, setupLog, err = this.setup(ctx, params)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ToolResult{Content: setupLog}, fmt.Errorf("setup timed out after %s:\n%s", timeout, setupLog)
+		}
+		if err != nil {
+			return ToolResult{Content: setupLog}, fmt.Errorf("%v\n%s", err, setupLog)
+		}
+	}
+
+	program := script
+	if this.FileExtension != "" {
+		// Next to the project's own code, so the script can import it (Python
+		// puts the script's directory first on sys.path, Node resolves
+		// relative requires from it); the temp dir if that's read-only.
+		file, err := os.CreateTemp(cmp.Or(dir, "."), ".cli-ai-agent-*"+this.FileExtension)
+		if err != nil {
+			file, err = os.CreateTemp("", "cli-ai-agent-*"+this.FileExtension)
+		}
+		if err != nil {
+			return ToolResult{}, err
+		}
+		defer func() { _ = os.Remove(file.Name()) }()
+		_, err = file.WriteString(script)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return ToolResult{}, err
+		}
+		program = file.Name()
+	}
+
+	cmd := exec.CommandContext(ctx, interpreter, slices.Concat(this.InterpreterArgs, []string{program}, args)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.WaitDelay = time.Second
+	killProcessGroupOnCancel(cmd)
+	output, err := cmd.CombinedOutput()
+	result := string(output)
+	if this.setup != nil {
+		result = "=== " + cmp.Or(this.setupHeading, "setup") + " ===\n" + setupLog + "\n=== script output ===\n" + result
+	}
+	result = withWorkingDirHeader(dir, result)
+	metadata := commandMetadata(ctx, cmd)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ToolResult{Content: result, Metadata: metadata}, fmt.Errorf("%s script timed out after %s; output so far:\n%s", this.language(), timeout, result)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ToolResult{Content: result, Metadata: metadata}, fmt.Errorf("%s script canceled (%v); output so far:\n%s", this.language(), context.Cause(ctx), result)
+	}
+	if err != nil {
+		return ToolResult{Content: result, Metadata: metadata}, fmt.Errorf("%s execution failed: %v\n%s", this.language(), err, result)
+	}
+	return ToolResult{Content: result, Metadata: metadata}, nil
+}
+
+// language names the interpreter for messages: "python3" -> "python".
+func (this *ScriptTool) language() string {
+	base := strings.TrimSuffix(filepath.Base(this.Interpreter), filepath.Ext(this.Interpreter))
+	if trimmed := strings.TrimRight(base, "0123456789."); trimmed != "" {
+		return trimmed
+	}
+	return base
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// SessionState exposes agent-level session state to tools.
+type SessionState interface {
+	// WasRead reports whether the file at path was read during this session.
+	WasRead(path string) bool
+	// MarkRead records that the file at path has been read (or its content otherwise shown to the model).
+	MarkRead(path string)
+	// RecordWrite remembers the content a tool last wrote to path.
+	RecordWrite(path, content string)
+	// LastWrite returns the content a tool last wrote to path, if any.
+	LastWrite(path string) (content string, ok bool)
+	// RecordEdit remembers what path held before a tool changed it (existed is
+	// false if the tool created it), so the edit can be undone.
+	RecordEdit(path string, before []byte, existed bool)
+}
+
+// ContextAware is implemented by tools that want access to the agent's session
+// state. The agent injects it when the tool is registered.
+type ContextAware interface {
+	SetSession(state SessionState)
+}
+
+// Session is embedded by tools to become ContextAware. Its zero value behaves as
+// if no session were attached.
+type Session struct {
+	state SessionState
+}
+
+func (this *Session) SetSession(state SessionState) { this.state = state }
+
+func (this *Session) wasRead(path string) bool {
+	return this.state == nil || this.state.WasRead(path)
+}
+func (this *Session) markRead(path string) {
+	if this.state != nil {
+		this.state.MarkRead(path)
+	}
+}
+func (this *Session) recordWrite(path, content string) {
+	if this.state != nil {
+		this.state.RecordWrite(path, content)
+	}
+}
+
+// recordEdit reports path's content before an edit, given the result of
+// reading it then. Nothing is recorded when the read failed for a reason other
+// than the file not existing, since the earlier state is unknown.
+func (this *Session) recordEdit(path string, before []byte, readErr error) {
+	if this.state == nil || (readErr != nil && !errors.Is(readErr, fs.ErrNotExist)) {
+		return
+	}
+	this.state.RecordEdit(path, before, readErr == nil)
+}
+func (this *Session) lastWrite(path string) (string, bool) {
+	if this.state == nil {
+		return "", false
+	}
+	return this.state.LastWrite(path)
+}
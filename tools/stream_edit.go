@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxStreamEditFileBytes = 1024 * 1024
+	maxStreamEditDiffBytes = 32 * 1024
+)
+
+// StreamEditTool applies sed-style substitutions (s/old/new/flags) across the
+// files under a directory, returning a per-file summary and a diff preview.
+type StreamEditTool struct {
+	Sandbox
+}
+
+func (this *StreamEditTool) Name() string { return "stream_edit" }
+func (this *StreamEditTool) Description() string {
+	return "Apply sed-style regex substitutions (e.g. 's/oldName/newName/g') to every matching file under a directory, line by line. " +
+		"Supports any delimiter, flags g (all matches per line) and i (case-insensitive), and \\1 or & in replacements. " +
+		"Returns a per-file summary and a diff; with preview=true nothing is written."
+}
+func (this *StreamEditTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"root": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory (or single file) to edit",
+			},
+			"expressions": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Substitution expressions applied in order, e.g. [\"s/foo/bar/g\"]",
+			},
+			"glob": map[string]interface{}{
+				"type":        "string",
+				"description": "Only edit files whose name (or relative path) matches this glob, e.g. '*.go' (optional)",
+			},
+			"preview": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only report what would change, without writing (optional, default false)",
+			},
+		},
+		"required": []string{"root", "expressions"},
+	}
+}
+func (this *StreamEditTool) RequiresPermission() bool { return true }
+func (this *StreamEditTool) RequiresPermissionFor(params map[string]interface{}) bool {
+	preview, _ := GetBool(params, "preview", false)
+	return !preview
+}
+func (this *StreamEditTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	root, err := GetNonEmptyString(params, "root")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	expressions, err := GetStringSlice(params, "expressions")
+	if err != nil || len(expressions) == 0 {
+		return ToolResult{}, errors.New("expressions parameter must be a non-empty array of strings")
+	}
+	var substitutions []substitution
+	for _, expression := range expressions {
+		parsed, err := parseSubstitution(expression)
+		if err != nil {
+			return ToolResult{}, err
+		}
+		substitutions = append(substitutions, parsed)
+	}
+	glob, err := GetOptionalString(params, "glob", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	preview, err := GetBool(params, "preview", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	var summary strings.Builder
+	var diffs strings.Builder
+	var filesChanged, totalReplacements int
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !matchesFileGlob(glob, root, path) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() > maxStreamEditFileBytes {
+			return nil
+		}
+		original, err := os.ReadFile(path)
+		if err != nil || !utf8.Valid(original) {
+			return nil
+		}
+		edited, replacements := applySubstitutions(string(original), substitutions)
+		if replacements == 0 || edited == string(original) {
+			return nil
+		}
+		filesChanged++
+		totalReplacements += replacements
+		_, _ = fmt.Fprintf(&summary, "%s: %d substitution(s)\n", path, replacements)
+		diffs.WriteString(unifiedDiff(path, path, string(original), edited))
+		if preview {
+			return nil
+		}
+		return os.WriteFile(path, []byte(edited), info.Mode().Perm())
+	})
+	if err != nil {
+		return ToolResult{Content: summary.String()}, err
+	}
+	if filesChanged == 0 {
+		return ToolResult{Content: "No matches; nothing to change."}, nil
+	}
+	verb := "Edited"
+	if preview {
+		verb = "Preview (nothing written): would edit"
+	}
+	diff := diffs.String()
+	if len(diff) > maxStreamEditDiffBytes {
+		diff = diff[:maxStreamEditDiffBytes] + "\n[diff truncated]\n"
+	}
+	return ToolResult{Content: fmt.Sprintf("%s %d file(s), %d substitution(s):\n%s\n%s", verb, filesChanged, totalReplacements, summary.String(), diff)}, nil
+}
+
+// matchesFileGlob reports whether path (under root) matches glob by base name or relative path.
+func matchesFileGlob(glob, root, path string) bool {
+	if glob == "" {
+		return true
+	}
+	if matched, _ := filepath.Match(glob, filepath.Base(path)); matched {
+		return true
+	}
+	relative, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	matched, _ := filepath.Match(glob, filepath.ToSlash(relative))
+	return matched
+}
+
+type substitution struct {
+	pattern     *regexp.Regexp
+	replacement string
+	global      bool
+}
+
+// parseSubstitution parses a sed-style "s/pattern/replacement/flags" expression.
+func parseSubstitution(expression string) (result substitution, err error) {
+	if len(expression) < 4 || expression[0] != 's' {
+		return result, fmt.Errorf("invalid expression %q: expected s/pattern/replacement/flags", expression)
+	}
+	delimiter, size := utf8.DecodeRuneInString(expression[1:])
+	parts := splitUnescaped(expression[1+size:], delimiter)
+	if len(parts) != 3 {
+		return result, fmt.Errorf("invalid expression %q: expected s%cpattern%creplacement%cflags", expression, delimiter, delimiter, delimiter)
+	}
+	pattern, replacement, flags := parts[0], parts[1], parts[2]
+	prefix := ""
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			result.global = true
+		case 'i':
+			prefix = "(?i)"
+		default:
+			return result, fmt.Errorf("invalid expression %q: unsupported flag %q", expression, flag)
+		}
+	}
+	result.pattern, err = regexp.Compile(prefix + pattern)
+	if err != nil {
+		return result, fmt.Errorf("invalid expression %q: %v", expression, err)
+	}
+	result.replacement = sedReplacement(replacement)
+	return result, nil
+}
+
+// splitUnescaped splits on delimiter, treating a backslash-escaped delimiter as literal.
+func splitUnescaped(text string, delimiter rune) (parts []string) {
+	var current strings.Builder
+	escaped := false
+	for _, char := range text {
+		switch {
+		case escaped && char == delimiter:
+			current.WriteRune(char)
+			escaped = false
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == delimiter:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	return append(parts, current.String())
+}
+
+// sedReplacement converts sed replacement syntax (\1, &, \&, \n) into a
+// regexp.Expand template.
+func sedReplacement(replacement string) string {
+	var result strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		char := replacement[i]
+		switch {
+		case char == '\\' && i+1 < len(replacement):
+			i++
+			next := replacement[i]
+			switch {
+			case '0' <= next && next <= '9':
+				result.WriteString("${" + string(next) + "}")
+			case next == 'n':
+				result.WriteByte('\n')
+			case next == 't':
+				result.WriteByte('\t')
+			default:
+				result.WriteByte(next)
+			}
+		case char == '&':
+			result.WriteString("${0}")
+		case char == '$':
+			result.WriteString("$$")
+		default:
+			result.WriteByte(char)
+		}
+	}
+	return result.String()
+}
+
+func applySubstitutions(content string, substitutions []substitution) (string, int) {
+	lines := strings.SplitAfter(content, "\n")
+	var count int
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\n")
+		ending := line[len(body):]
+		for _, substitution := range substitutions {
+			var replaced int
+			body, replaced = substitution.apply(body)
+			count += replaced
+		}
+		lines[i] = body + ending
+	}
+	return strings.Join(lines, ""), count
+}
+
+func (this substitution) apply(line string) (string, int) {
+	matches := this.pattern.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) == 0 {
+		return line, 0
+	}
+	if !this.global {
+		matches = matches[:1]
+	}
+	var result []byte
+	last := 0
+	for _, match := range matches {
+		result = append(result, line[last:match[0]]...)
+		result = this.pattern.ExpandString(result, this.replacement, line, match)
+		last = match[1]
+	}
+	result = append(result, line[last:]...)
+	return string(result), len(matches)
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFiles creates the files (relative path to content) under dir.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+var streamEditFiles = map[string]string{
+	"a.go":      "package a\n\nfunc oldName() {}\n",
+	"sub/b.go":  "package sub\n\n// oldName and OLDNAME\nvar x = oldName\n",
+	"notes.txt": "oldName stays here\n",
+	".git/c.go": "oldName in a hidden directory\n",
+	"sub/d.go":  "package sub\n",
+}
+
+func TestStreamEditAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, streamEditFiles)
+	tool := &StreamEditTool{Sandbox: Sandbox{Root: dir}}
+	params := map[string]interface{}{"root": ".", "expressions": []interface{}{"s/oldname/newName/gi", "s|var x|var y|"}, "glob": "*.go"}
+
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"a.go":      "package a\n\nfunc newName() {}\n",
+		"sub/b.go":  "package sub\n\n// newName and newName\nvar y = newName\n",
+		"notes.txt": streamEditFiles["notes.txt"],
+		".git/c.go": streamEditFiles[".git/c.go"],
+		"sub/d.go":  streamEditFiles["sub/d.go"],
+	}
+	for name, content := range want {
+		if got := readTestFile(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("%s is %q, want %q", name, got, content)
+		}
+	}
+	for _, line := range []string{filepath.Join(dir, "a.go") + ": 1 substitution(s)", filepath.Join(dir, "sub/b.go") + ": 4 substitution(s)", "+var y = newName"} {
+		if !strings.Contains(result.Content, line) {
+			t.Errorf("%q missing from\n%s", line, result.Content)
+		}
+	}
+}
+
+func TestStreamEditPreviewWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, streamEditFiles)
+	tool := &StreamEditTool{Sandbox: Sandbox{Root: dir}}
+	params := map[string]interface{}{"root": ".", "expressions": []interface{}{"s/oldName/newName/"}, "preview": true}
+	if tool.RequiresPermissionFor(params) {
+		t.Error("a preview shouldn't need permission")
+	}
+
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, "Preview (nothing written)") || !strings.Contains(result.Content, "+func newName() {}") {
+		t.Errorf("unexpected preview:\n%s", result.Content)
+	}
+	for name, content := range streamEditFiles {
+		if got := readTestFile(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("preview changed %s to %q", name, got)
+		}
+	}
+}
+
+func TestParseSubstitutionRejectsMalformed(t *testing.T) {
+	for _, expression := range []string{"s/a/b", "y/a/b/", "s/(/x/", "s/a/b/q"} {
+		if _, err := parseSubstitution(expression); err == nil {
+			t.Errorf("%q was accepted", expression)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxStructuralResults = 200
+
+// StructuralSearchTool finds Go declarations by structure (receiver, result
+// types, kind, method set) rather than by text.
+type StructuralSearchTool struct {
+	Sandbox
+}
+
+func (this *StructuralSearchTool) Name() string { return "structural_search" }
+func (this *StructuralSearchTool) Description() string {
+	return "Search Go source structurally and return matches as file:line. Query syntax: a kind ('func' or 'type') followed by key=value filters. " +
+		"func filters: receiver=T (methods on T or *T; receiver=none for plain functions), returns=X (a result type, e.g. error or *Config), name=REGEX. " +
+		"type filters: kind=struct|interface|func|map|slice|alias|other, implements=I (types whose methods include every method declared in interface I; names only, embedded interfaces ignored), name=REGEX. " +
+		"Examples: 'func receiver=Agent', 'func returns=error name=^New', 'type implements=Tool'. Only syntax is analyzed; no type checking."
+}
+func (this *StructuralSearchTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "The structural query, e.g. 'func receiver=Agent' or 'type implements=Tool'",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory (or .go file) to search (optional, default '.')",
+			},
+		},
+		"required": []string{"query"},
+	}
+}
+func (this *StructuralSearchTool) RequiresPermission() bool { return false }
+func (this *StructuralSearchTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	rawQuery, err := GetNonEmptyString(params, "query")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	query, err := parseStructuralQuery(rawQuery)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	root, err := GetOptionalString(params, "path", "")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err = this.Resolve(root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	index, err := indexGoDeclarations(ctx, root)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	results := newCappedResults(maxStructuralResults)
+	switch query.kind {
+	case "func":
+		for _, fn := range index.funcs {
+			if query.matchesFunc(fn) {
+				results.Add(fmt.Sprintf("%s:%d: %s", fn.file, fn.line, fn.signature))
+			}
+		}
+	case "type":
+		var required []string
+		if name := query.filters["implements"]; name != "" {
+			methods, ok := index.interfaces[name]
+			if !ok {
+				return ToolResult{}, fmt.Errorf("interface %s not found under %s", name, root)
+			}
+			required = methods
+		}
+		for _, declared := range index.types {
+			if query.matchesType(declared, index.methods[declared.name], required) {
+				results.Add(fmt.Sprintf("%s:%d: type %s (%s)", declared.file, declared.line, declared.name, declared.kind))
+			}
+		}
+	}
+	return ToolResult{Content: results.String("matches")}, nil
+}
+
+type structuralQuery struct {
+	kind    string
+	filters map[string]string
+	name    *regexp.Regexp
+}
+
+var structuralFilters = map[string][]string{
+	"func": {"receiver", "returns", "name"},
+	"type": {"kind", "implements", "name"},
+}
+
+func parseStructuralQuery(raw string) (query structuralQuery, err error) {
+	fields := strings.Fields(raw)
+	query.kind = strings.TrimSuffix(strings.ToLower(fields[0]), "s")
+	allowed, ok := structuralFilters[query.kind]
+	if !ok {
+		return query, fmt.Errorf("unknown kind %q: a query starts with 'func' or 'type'", fields[0])
+	}
+	query.filters = make(map[string]string)
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return query, fmt.Errorf("invalid filter %q: expected key=value", field)
+		}
+		if !contains(allowed, key) {
+			return query, fmt.Errorf("unknown %s filter %q (allowed: %s)", query.kind, key, strings.Join(allowed, ", "))
+		}
+		query.filters[key] = value
+	}
+	if pattern := query.filters["name"]; pattern != "" {
+		if query.name, err = regexp.Compile(pattern); err != nil {
+			return query, fmt.Errorf("invalid name pattern: %v", err)
+		}
+	}
+	return query, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (this structuralQuery) matchesFunc(fn declaredFunc) bool {
+	if this.name != nil && !this.name.MatchString(fn.name) {
+		return false
+	}
+	if receiver, ok := this.filters["receiver"]; ok {
+		if receiver == "none" {
+			if fn.receiver != "" {
+				return false
+			}
+		} else if fn.receiver != strings.TrimPrefix(receiver, "*") {
+			return false
+		}
+	}
+	if returns, ok := this.filters["returns"]; ok && !contains(fn.results, returns) {
+		return false
+	}
+	return true
+}
+
+func (this structuralQuery) matchesType(declared declaredType, methods map[string]bool, required []string) bool {
+	if this.name != nil && !this.name.MatchString(declared.name) {
+		return false
+	}
+	if kind, ok := this.filters["kind"]; ok && declared.kind != kind {
+		return false
+	}
+	if _, ok := this.filters["implements"]; ok {
+		if declared.kind == "interface" || len(required) == 0 {
+			return false
+		}
+		for _, method := range required {
+			if !methods[method] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type declaredFunc struct {
+	file, name, receiver, signature string
+	line                            int
+	results                         []string
+}
+
+type declaredType struct {
+	file, name, kind string
+	line             int
+}
+
+type goDeclarations struct {
+	funcs      []declaredFunc
+	types      []declaredType
+	methods    map[string]map[string]bool // receiver type name -> method names
+	interfaces map[string][]string        // interface name -> declared method names
+}
+
+func indexGoDeclarations(ctx context.Context, root string) (*goDeclarations, error) {
+	index := &goDeclarations{methods: make(map[string]map[string]bool), interfaces: make(map[string][]string)}
+	fileSet := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		file, err := parser.ParseFile(fileSet, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil // skip files that don't parse
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil || relative == "." {
+			relative = path
+		}
+		index.add(fileSet, relative, file)
+		return nil
+	})
+	sort.SliceStable(index.funcs, func(i, j int) bool { return index.funcs[i].file < index.funcs[j].file })
+	sort.SliceStable(index.types, func(i, j int) bool { return index.types[i].file < index.types[j].file })
+	return index, err
+}
+
+func (this *goDeclarations) add(fileSet *token.FileSet, path string, file *ast.File) {
+	for _, declaration := range file.Decls {
+		switch declaration := declaration.(type) {
+		case *ast.FuncDecl:
+			fn := declaredFunc{file: path, name: declaration.Name.Name, line: fileSet.Position(declaration.Pos()).Line}
+			if declaration.Recv != nil && len(declaration.Recv.List) > 0 {
+				fn.receiver = receiverTypeName(declaration.Recv.List[0].Type)
+				if this.methods[fn.receiver] == nil {
+					this.methods[fn.receiver] = make(map[string]bool)
+				}
+				this.methods[fn.receiver][fn.name] = true
+			}
+			if declaration.Type.Results != nil {
+				for _, result := range declaration.Type.Results.List {
+					resultType := types.ExprString(result.Type)
+					for range max(1, len(result.Names)) {
+						fn.results = append(fn.results, resultType)
+					}
+				}
+			}
+			fn.signature = funcSignature(declaration)
+			this.funcs = append(this.funcs, fn)
+		case *ast.GenDecl:
+			for _, spec := range declaration.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				declared := declaredType{file: path, name: typeSpec.Name.Name, line: fileSet.Position(typeSpec.Pos()).Line, kind: typeKind(typeSpec)}
+				if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+					for _, method := range iface.Methods.List {
+						for _, name := range method.Names {
+							this.interfaces[declared.name] = append(this.interfaces[declared.name], name.Name)
+						}
+					}
+				}
+				this.types = append(this.types, declared)
+			}
+		}
+	}
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(expr.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+func typeKind(spec *ast.TypeSpec) string {
+	if spec.Assign.IsValid() {
+		return "alias"
+	}
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		return "slice"
+	default:
+		return "other"
+	}
+}
+
+func funcSignature(declaration *ast.FuncDecl) string {
+	var result strings.Builder
+	result.WriteString("func ")
+	if declaration.Recv != nil && len(declaration.Recv.List) > 0 {
+		result.WriteString("(" + types.ExprString(declaration.Recv.List[0].Type) + ") ")
+	}
+	result.WriteString(declaration.Name.Name)
+	result.WriteString(strings.TrimPrefix(types.ExprString(declaration.Type), "func"))
+	return result.String()
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStructuralSearch(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string // the matches, as file:line prefixes
+	}{
+		{query: "func receiver=Square", want: []string{"shapes.go:12:", "shapes.go:13:"}},
+		{query: "func receiver=Circle", want: []string{"shapes.go:17:"}},
+		{query: "func returns=error", want: []string{"shapes.go:19:", "shapes.go:26:"}},
+		{query: "func returns=*Square", want: []string{"shapes.go:19:"}},
+		{query: "func receiver=none name=^[a-z]", want: []string{"shapes.go:28:"}},
+		{query: "type implements=Shape", want: []string{"shapes.go:10:"}},
+	}
+	tool := &StructuralSearchTool{Sandbox: Sandbox{Root: "testdata/structural"}}
+	for _, test := range cases {
+		t.Run(test.query, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), map[string]interface{}{"query": test.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, line := range strings.Split(result.Content, "\n") {
+				if _, position, ok := strings.Cut(line, "shapes.go:"); ok {
+					line, _, _ := strings.Cut(position, " ")
+					got = append(got, "shapes.go:"+line)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("got %v, want %v in\n%s", got, test.want, result.Content)
+			}
+		})
+	}
+}
+
+func TestStructuralQueryErrors(t *testing.T) {
+	for _, query := range []string{"var x", "func color=red", "func name=("} {
+		if _, err := parseStructuralQuery(query); err == nil {
+			t.Errorf("%q was accepted", query)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+package untested
+
+func Double(a int) int { return 2 * a }
@@ -0,0 +1,28 @@
+package shapes
+
+import "errors"
+
+type Shape interface {
+	Area() float64
+	Name() string
+}
+
+type Square struct{ Side float64 }
+
+func (this Square) Area() float64 { return this.Side * this.Side }
+func (this Square) Name() string  { return "square" }
+
+type Circle struct{ Radius float64 }
+
+func (this *Circle) Area() float64 { return 3.14 * this.Radius * this.Radius }
+
+func NewSquare(side float64) (*Square, error) {
+	if side < 0 {
+		return nil, errors.New("negative side")
+	}
+	return &Square{Side: side}, nil
+}
+
+func Validate(shape Shape) error { return nil }
+
+func describe(shape Shape) string { return shape.Name() }
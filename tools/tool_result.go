@@ -0,0 +1,19 @@
+package tools
+
+// ToolResult is what a tool's Execute returns. Content is what the model is
+// shown; Metadata carries details the agent uses internally (see the Meta*
+// keys).
+type ToolResult struct {
+	Content  string
+	Metadata map[string]interface{}
+	IsError  bool // Content reports a failure; the agent sets it when Execute returns an error
+}
+
+// Metadata keys set by the built-in tools.
+const (
+	MetaPath         = "path"          // string: the file written or read (resolved against the sandbox)
+	MetaBytesWritten = "bytes_written" // int: the size of the file after a write
+	MetaExitCode     = "exit_code"     // int: a command's exit status (-1 if it didn't exit normally)
+	MetaTimedOut     = "timed_out"     // bool: the command was killed at its timeout
+	MetaTruncated    = "truncated"     // bool: output was cut short at a limit
+)
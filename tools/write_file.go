@@ -1,18 +1,29 @@
 package tools
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 )
 
 // WriteFileTool implements file writing
-type WriteFileTool struct{}
+type WriteFileTool struct {
+	Sandbox
+	Session
+	CreateDirs bool // create missing parent directories unless the call sets create_dirs to false (-auto-mkdir)
+}
 
 func (this *WriteFileTool) Name() string { return "write_file" }
 func (this *WriteFileTool) Description() string {
-	return "Write a file. If the file already exists, it will be overwritten."
+	return "Write a file. If the file already exists, it will be overwritten (or appended to, if 'append' is set)."
 }
 func (this *WriteFileTool) Parameters() map[string]interface{} {
+	createDirsDefault := "false"
+	if this.CreateDirs {
+		createDirsDefault = "true"
+	}
 	return map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
@@ -24,19 +35,115 @@ func (this *WriteFileTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "The content to write to the file.",
 			},
+			"append": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Append the content to the end of the file instead of overwriting it (optional, default false).",
+			},
+			"create_dirs": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Create the file's parent directories if they don't exist (optional, default " + createDirsDefault + ").",
+			},
 		},
-		"required": []string{"path"},
+		"required": []string{"path", "content"},
 	}
 }
-func (this *WriteFileTool) Execute(params map[string]interface{}) (string, error) {
-	path, ok := params["path"].(string)
-	if !ok {
-		return "", errors.New("path parameter must be a string")
+func (this *WriteFileTool) Execute(ctx context.Context, params map[string]interface{}) (ToolResult, error) {
+	path, err := GetString(params, "path")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	replace, err := GetString(params, "content")
+	if err != nil {
+		return ToolResult{}, err
+	}
+	appending, err := GetBool(params, "append", false)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	createDirs, err := GetBool(params, "create_dirs", this.CreateDirs)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	path, err = this.Resolve(path)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	var note string
+	if createDirs {
+		created, err := createParentDirs(path)
+		if err != nil {
+			return ToolResult{}, err
+		}
+		if created != "" {
+			note = fmt.Sprintf("[created directory %s]\n", created)
+		}
+	}
+	before, readErr := os.ReadFile(path)
+	if appending {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return ToolResult{}, err
+		}
+		defer func() { _ = file.Close() }()
+		if _, err = file.WriteString(replace); err != nil {
+			return ToolResult{}, err
+		}
+		this.recordEdit(path, before, readErr)
+		result := ToolResult{Content: note + replace, Metadata: map[string]interface{}{MetaPath: path}}
+		if written, err := os.ReadFile(path); err == nil {
+			this.recordWrite(path, string(written))
+			result.Metadata[MetaBytesWritten] = len(written)
+		}
+		return result, nil
 	}
-	replace, ok := params["content"].(string)
-	if !ok {
-		return "", errors.New("content parameter must be a string")
+	err = os.WriteFile(path, []byte(replace), 0644)
+	if err == nil {
+		this.markRead(path)
+		this.recordWrite(path, replace)
+		this.recordEdit(path, before, readErr)
 	}
-	return replace, os.WriteFile(path, []byte(replace), 0644)
+	return ToolResult{Content: note + replace, Metadata: map[string]interface{}{MetaPath: path, MetaBytesWritten: len(replace)}}, err
+}
+
+// createParentDirs creates the missing directories above path and returns the
+// highest one it created ("" when none were missing). Sandboxed tools pass a
+// resolved path, so whatever is created lies inside the root.
+func createParentDirs(path string) (string, error) {
+	dir := filepath.Dir(path)
+	created := ""
+	for missing := dir; ; missing = filepath.Dir(missing) {
+		if _, err := os.Stat(missing); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		created = missing
+		if filepath.Dir(missing) == missing {
+			break
+		}
+	}
+	if created == "" {
+		return "", nil
+	}
+	return created, os.MkdirAll(dir, 0755)
 }
 func (this *WriteFileTool) RequiresPermission() bool { return true }
+
+// ReplayKey identifies the operation so the agent can avoid applying it twice
+// in one turn. The path is resolved and made absolute, so "a.txt" and
+// "./a.txt" name the same file.
+func (this *WriteFileTool) ReplayKey(params map[string]interface{}) (path, key string) {
+	path, _ = GetString(params, "path")
+	if resolved, err := this.Resolve(path); err == nil {
+		path = resolved
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	content, _ := GetString(params, "content")
+	operation := "write"
+	if appending, _ := GetBool(params, "append", false); appending {
+		operation = "append"
+	}
+	return path, operation + "\x00" + content
+}
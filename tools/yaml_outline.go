@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yamlNode is one entry of a YAML document as understood by parseYAMLOutline:
+// a mapping key (with an inline scalar value and/or nested entries) or a
+// sequence item. It captures structure only; scalars are left as raw text.
+type yamlNode struct {
+	key      string
+	value    string
+	line     int
+	indent   int
+	isItem   bool
+	children []*yamlNode // mapping entries
+	items    []*yamlNode // sequence items
+}
+
+func (this *yamlNode) child(key string) *yamlNode {
+	if this == nil {
+		return nil
+	}
+	for _, child := range this.children {
+		if child.key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+// path follows a dotted series of keys, e.g. "metadata.name".
+func (this *yamlNode) path(keys string) *yamlNode {
+	node := this
+	for _, key := range strings.Split(keys, ".") {
+		node = node.child(key)
+	}
+	return node
+}
+
+type lintFinding struct {
+	line     int
+	severity string // "error" or "warning"
+	message  string
+}
+
+func (this lintFinding) String() string {
+	return fmt.Sprintf("%d: %s: %s", this.line, this.severity, this.message)
+}
+
+var (
+	yamlKeyLine     = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s"'#\-{\[][^#]*?|-[^\s#][^#]*?)\s*:(?:\s+(.*))?$`)
+	yamlBlockScalar = regexp.MustCompile(`^[|>][+-]?\d*$`)
+)
+
+type yamlFrame struct {
+	indent int
+	node   *yamlNode
+}
+
+// parseYAMLOutline parses the block structure of a (possibly multi-document)
+// YAML file, reporting problems a YAML parser would reject or that are almost
+// certainly mistakes: tab indentation, inconsistent indentation, duplicate
+// keys, unterminated quotes or brackets, and lines that are neither entries
+// nor continuations. Flow collections and scalars are not interpreted.
+func parseYAMLOutline(content string) (documents []*yamlNode, findings []lintFinding) {
+	root := &yamlNode{indent: -1}
+	documents = append(documents, root)
+	stack := []yamlFrame{{indent: -1, node: root}}
+	problem := func(line int, format string, args ...interface{}) {
+		findings = append(findings, lintFinding{line: line, severity: "error", message: fmt.Sprintf(format, args...)})
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	blockIndent := -1 // indentation of the key owning a block scalar being skipped
+	flowDepth := 0    // unclosed brackets of a flow collection spanning lines
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		raw := lines[i]
+		trimmed := strings.TrimSpace(raw)
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if flowDepth > 0 {
+			flowDepth += bracketBalance(stripYAMLComment(trimmed))
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "%") {
+			continue
+		}
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") || trimmed == "..." {
+			if len(root.children) > 0 || len(root.items) > 0 {
+				root = &yamlNode{indent: -1}
+				documents = append(documents, root)
+			}
+			stack = []yamlFrame{{indent: -1, node: root}}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(raw, " "), "\t") {
+			problem(number, "tab character used for indentation (YAML requires spaces)")
+			continue
+		}
+		text := stripYAMLComment(trimmed)
+		if root.line == 0 {
+			root.line = number // documents report missing fields at their first line
+		}
+		if quote := unterminatedQuote(text); quote != 0 {
+			problem(number, "unterminated %c-quoted string", quote)
+		}
+
+		// Each line is a sequence item ("- ..."), a mapping entry ("key: ..."),
+		// or the continuation of a multi-line plain scalar.
+		for text != "" {
+			if text == "-" || strings.HasPrefix(text, "- ") {
+				for len(stack) > 1 {
+					top := stack[len(stack)-1]
+					if top.indent < indent || (top.indent == indent && !top.node.isItem && top.node.value == "") {
+						break
+					}
+					stack = stack[:len(stack)-1]
+				}
+				parent := stack[len(stack)-1].node
+				if len(parent.children) > 0 {
+					problem(number, "sequence item mixed with mapping entries under %q", describeYAMLParent(parent))
+				}
+				if parent.value != "" {
+					problem(number, "sequence item under %q, which already has a value", describeYAMLParent(parent))
+				}
+				item := &yamlNode{line: number, indent: indent, isItem: true}
+				parent.items = append(parent.items, item)
+				stack = append(stack, yamlFrame{indent: indent, node: item})
+				rest := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+				indent += len(text) - len(strings.TrimLeft(text[1:], " "))
+				text = rest
+				continue
+			}
+
+			match := yamlKeyLine.FindStringSubmatch(text)
+			if match == nil {
+				top := stack[len(stack)-1]
+				switch {
+				case top.node.value != "" && indent > top.indent:
+					// continuation of a multi-line plain scalar
+				case top.node.isItem && top.node.value == "" && len(top.node.children) == 0 && top.node.line == number:
+					top.node.value = text
+				default:
+					problem(number, "expected 'key: value' or '- item', found %q", text)
+				}
+				if strings.ContainsAny(text[:1], "[{") {
+					flowDepth = bracketBalance(text)
+				}
+				break
+			}
+
+			for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1].node
+			key := strings.Trim(match[1], `"'`)
+			value := strings.TrimSpace(match[2])
+			if parent.value != "" && !parent.isItem {
+				problem(number, "%q is nested under %q, which already has a value", key, describeYAMLParent(parent))
+			}
+			if len(parent.items) > 0 {
+				problem(number, "mapping entry %q mixed with sequence items under %q", key, describeYAMLParent(parent))
+			}
+			if len(parent.children) > 0 && parent.children[0].indent != indent {
+				problem(number, "inconsistent indentation: %q is indented %d spaces but its siblings %d", key, indent, parent.children[0].indent)
+			}
+			if previous := parent.child(key); previous != nil {
+				problem(number, "duplicate key %q (first defined on line %d)", key, previous.line)
+			}
+			node := &yamlNode{key: key, value: value, line: number, indent: indent}
+			parent.children = append(parent.children, node)
+			stack = append(stack, yamlFrame{indent: indent, node: node})
+			if yamlBlockScalar.MatchString(value) {
+				blockIndent = indent
+			} else if value != "" && strings.ContainsAny(value[:1], "[{") {
+				flowDepth = bracketBalance(value)
+			}
+			break
+		}
+		if flowDepth < 0 {
+			problem(number, "unbalanced closing bracket")
+			flowDepth = 0
+		}
+	}
+	if flowDepth > 0 {
+		problem(len(lines), "unterminated flow collection ('[' or '{' never closed)")
+	}
+	return documents, findings
+}
+
+func describeYAMLParent(node *yamlNode) string {
+	if node.key != "" {
+		return node.key
+	}
+	if node.isItem {
+		return fmt.Sprintf("item on line %d", node.line)
+	}
+	return "document root"
+}
+
+// stripYAMLComment removes a trailing comment (a '#' at the start or after
+// whitespace, outside quotes).
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" :-[{,", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimSpace(text[:i])
+		}
+	}
+	return text
+}
+
+// unterminatedQuote returns the quote character of a quoted scalar that isn't
+// closed on the same line, or 0.
+func unterminatedQuote(text string) byte {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+					i++ // '' escapes a single quote
+					continue
+				}
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" :-[{,", rune(text[i-1])) {
+				quote = c
+			}
+		}
+	}
+	return quote
+}
+
+func bracketBalance(text string) (balance int) {
+	for _, c := range text {
+		switch c {
+		case '[', '{':
+			balance++
+		case ']', '}':
+			balance--
+		}
+	}
+	return balance
+}